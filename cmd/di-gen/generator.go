@@ -0,0 +1,477 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/TsvetanMilanov/go-simple-di/internal/tags"
+	"golang.org/x/tools/go/packages"
+)
+
+const directive = "//di:register"
+
+type config struct {
+	output   string
+	typeName string
+	funcName string
+}
+
+type result struct {
+	dir    string
+	source []byte
+}
+
+type registration struct {
+	named      *types.Named
+	name       string
+	field      string
+	injections []injection
+}
+
+// injection assigns the dependency to the field path of the registration.
+// When alloc is set the field is embedded pointer which is allocated with
+// new instance of alloc instead.
+type injection struct {
+	field string
+	dep   *registration
+	alloc types.Type
+}
+
+func (r *registration) String() string {
+	if len(r.name) > 0 {
+		return fmt.Sprintf("*%s (name: %s)", r.named.Obj().Name(), r.name)
+	}
+
+	return "*" + r.named.Obj().Name()
+}
+
+func generate(pattern string, cfg *config) (*result, error) {
+	pkg, err := load(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	regs, err := collect(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = wire(pkg, regs)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := render(pkg, regs, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{dir: filepath.Dir(pkg.GoFiles[0]), source: source}, nil
+}
+
+func load(pattern string) (*packages.Package, error) {
+	mode := packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+		packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps
+	pkgs, err := packages.Load(&packages.Config{Mode: mode}, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package for %s, found %d", pattern, len(pkgs))
+	}
+
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		return nil, pkg.Errors[0]
+	}
+
+	if len(pkg.GoFiles) == 0 {
+		return nil, fmt.Errorf("package %s has no Go files", pkg.PkgPath)
+	}
+
+	return pkg, nil
+}
+
+// collect returns the types annotated with the di:register directive in
+// the order of their declarations.
+func collect(pkg *packages.Package) ([]*registration, error) {
+	res := []*registration{}
+	fields := make(map[string]bool)
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				doc := typeSpec.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+
+				options, ok := findDirective(doc)
+				if !ok {
+					continue
+				}
+
+				reg, err := newRegistration(pkg, typeSpec, options)
+				if err != nil {
+					return nil, err
+				}
+
+				reg.field = uniqueName(exportedName(reg.named.Obj().Name()+"_"+reg.name), fields)
+				res = append(res, reg)
+			}
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no types annotated with %s found in %s", directive, pkg.PkgPath)
+	}
+
+	return res, nil
+}
+
+func findDirective(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+
+	for _, c := range doc.List {
+		if c.Text == directive {
+			return "", true
+		}
+
+		if strings.HasPrefix(c.Text, directive+" ") {
+			return strings.TrimSpace(strings.TrimPrefix(c.Text, directive)), true
+		}
+	}
+
+	return "", false
+}
+
+func newRegistration(pkg *packages.Package, spec *ast.TypeSpec, options string) (*registration, error) {
+	pos := pkg.Fset.Position(spec.Pos())
+	named, ok := pkg.TypesInfo.Defs[spec.Name].Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s should be defined type", pos, spec.Name.Name)
+	}
+
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return nil, fmt.Errorf("%s: %s should be struct", pos, spec.Name.Name)
+	}
+
+	opts, err := tags.Parse(options)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", pos, err.Error())
+	}
+
+	return &registration{named: named, name: opts.Name}, nil
+}
+
+// wire finds the dependencies of the marked fields of the registrations.
+func wire(pkg *packages.Package, regs []*registration) error {
+	errs := []string{}
+	for _, reg := range regs {
+		st := reg.named.Underlying().(*types.Struct)
+		w := &wiring{pkg: pkg, regs: regs, reg: reg, visited: map[*types.Struct]bool{st: true}}
+		w.fields(st, "", false)
+		errs = append(errs, w.errs...)
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+// wiring collects the injections of single registration.
+type wiring struct {
+	pkg     *packages.Package
+	regs    []*registration
+	reg     *registration
+	visited map[*types.Struct]bool
+	errs    []string
+}
+
+// fields wires the marked fields of the struct. The fields of untagged
+// embedded structs are wired recursively like the container does and the
+// embedded pointers are allocated before their fields are assigned.
+func (w *wiring) fields(st *types.Struct, path string, readOnly bool) {
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		fieldTags, err := tags.Lookup(reflect.StructTag(st.Tag(i)))
+		if fieldTags == nil && err == nil {
+			w.embedded(field, path, readOnly)
+			continue
+		}
+
+		prefix := fmt.Sprintf("%s: [*%s]", w.pkg.Fset.Position(field.Pos()), w.reg.named.Obj().Name())
+		if err != nil {
+			w.errs = append(w.errs, fmt.Sprintf("%s %s", prefix, err.Error()))
+			continue
+		}
+
+		err = checkOptions(fieldTags)
+		if err != nil {
+			w.errs = append(w.errs, fmt.Sprintf("%s %s for field %s", prefix, err.Error(), path+field.Name()))
+			continue
+		}
+
+		if readOnly {
+			w.errs = append(w.errs, fmt.Sprintf("%s cannot set field %s", prefix, path+field.Name()))
+			continue
+		}
+
+		dep, err := findDependency(w.regs, field, fieldTags.Name)
+		if err != nil {
+			w.errs = append(w.errs, fmt.Sprintf("%s %s", prefix, err.Error()))
+			continue
+		}
+
+		if dep == nil {
+			if !fieldTags.Optional {
+				// Plain values cannot be registered with annotations.
+				w.errs = append(w.errs, fmt.Sprintf("%s unable to find registered dependency: %s", prefix, field.Name()))
+			}
+
+			continue
+		}
+
+		w.reg.injections = append(w.reg.injections, injection{field: path + field.Name(), dep: dep})
+	}
+}
+
+func (w *wiring) embedded(field *types.Var, path string, readOnly bool) {
+	if !field.Embedded() {
+		return
+	}
+
+	t := field.Type()
+	ptr, isPtr := t.Underlying().(*types.Pointer)
+	if isPtr {
+		t = ptr.Elem()
+	}
+
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || w.visited[st] {
+		return
+	}
+
+	w.visited[st] = true
+	defer delete(w.visited, st)
+
+	start := len(w.reg.injections)
+	fieldPath := path + field.Name()
+	// The generated code cannot allocate unexported embedded pointers like
+	// the container or reference unexported fields of other packages.
+	inaccessible := !field.Exported() && (isPtr || field.Pkg() != w.pkg.Types)
+	w.fields(st, fieldPath+".", readOnly || inaccessible)
+	if !isPtr || len(w.reg.injections) == start {
+		return
+	}
+
+	// The embedded pointer of new instance is always nil.
+	alloc := injection{field: fieldPath, alloc: t}
+	w.reg.injections = append(w.reg.injections[:start], append([]injection{alloc}, w.reg.injections[start:]...)...)
+}
+
+// checkOptions checks for di tag options which the container or di-gen do
+// not support.
+func checkOptions(t *tags.Tags) error {
+	err := t.Validate()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case t.Required:
+		return errors.New("unsupported di tag option 'required'")
+	case t.New:
+		return errors.New("unsupported di tag option 'new'")
+	case t.HasDefault:
+		return errors.New("unsupported di tag option 'default'")
+	case len(t.Env) > 0:
+		return errors.New("unsupported di tag option 'env'")
+	case len(t.Config) > 0:
+		return errors.New("unsupported di tag option 'config'")
+	}
+
+	return nil
+}
+
+// findDependency returns the registration which is injected in the field or
+// nil if there is no such registration.
+func findDependency(regs []*registration, field *types.Var, name string) (*registration, error) {
+	if !field.Exported() {
+		return nil, fmt.Errorf("cannot set field %s", field.Name())
+	}
+
+	switch t := field.Type().Underlying().(type) {
+	case *types.Pointer:
+		for _, reg := range regs {
+			if reg.name == name && types.Identical(t.Elem(), reg.named) {
+				return reg, nil
+			}
+		}
+	case *types.Interface:
+		candidates := []string{}
+		var res *registration
+		for _, reg := range regs {
+			if len(name) > 0 && reg.name != name {
+				continue
+			}
+
+			if types.Implements(types.NewPointer(reg.named), t) {
+				res = reg
+				candidates = append(candidates, reg.String())
+			}
+		}
+
+		if len(candidates) > 1 {
+			return nil, fmt.Errorf("ambiguous dependency: %s can be satisfied by %s",
+				field.Name(), strings.Join(candidates, ", "))
+		}
+
+		if res != nil {
+			return res, nil
+		}
+	case *types.Struct:
+		return nil, fmt.Errorf("cannot set field %s", field.Name())
+	}
+
+	return nil, nil
+}
+
+func render(pkg *packages.Package, regs []*registration, cfg *config) ([]byte, error) {
+	// imports contains the unique names of the imported packages by path.
+	imports := map[string]string{}
+	names := map[string]bool{}
+	qualifier := func(p *types.Package) string {
+		if p.Path() == pkg.PkgPath {
+			return ""
+		}
+
+		if name, ok := imports[p.Path()]; ok {
+			return name
+		}
+
+		name := p.Name()
+		for i := 2; names[name]; i++ {
+			name = fmt.Sprintf("%s%d", p.Name(), i)
+		}
+
+		names[name] = true
+		imports[p.Path()] = name
+		return name
+	}
+
+	body := new(bytes.Buffer)
+	fmt.Fprintf(body, "// %s contains the dependencies registered with %s.\n", cfg.typeName, directive)
+	fmt.Fprintf(body, "type %s struct {\n", cfg.typeName)
+	for _, reg := range regs {
+		fmt.Fprintf(body, "%s *%s\n", reg.field, reg.named.Obj().Name())
+	}
+	fmt.Fprintln(body, "}")
+	fmt.Fprintln(body)
+
+	fmt.Fprintf(body, "// %s creates and wires the dependencies registered with %s.\n", cfg.funcName, directive)
+	fmt.Fprintf(body, "func %s() *%s {\n", cfg.funcName, cfg.typeName)
+	fmt.Fprintf(body, "g := &%s{\n", cfg.typeName)
+	for _, reg := range regs {
+		fmt.Fprintf(body, "%s: new(%s),\n", reg.field, reg.named.Obj().Name())
+	}
+	fmt.Fprintln(body, "}")
+	for _, reg := range regs {
+		if len(reg.injections) == 0 {
+			continue
+		}
+
+		fmt.Fprintln(body)
+		for _, inj := range reg.injections {
+			if inj.alloc != nil {
+				fmt.Fprintf(body, "g.%s.%s = new(%s)\n", reg.field, inj.field, types.TypeString(inj.alloc, qualifier))
+				continue
+			}
+
+			fmt.Fprintf(body, "g.%s.%s = g.%s\n", reg.field, inj.field, inj.dep.field)
+		}
+	}
+	fmt.Fprintln(body)
+	fmt.Fprintln(body, "return g")
+	fmt.Fprintln(body, "}")
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintln(buf, "// Code generated by di-gen. DO NOT EDIT.")
+	fmt.Fprintln(buf)
+	fmt.Fprintf(buf, "package %s\n\n", pkg.Name)
+	if len(imports) > 0 {
+		fmt.Fprintln(buf, "import (")
+		paths := make([]string, 0, len(imports))
+		for importPath := range imports {
+			paths = append(paths, importPath)
+		}
+
+		sort.Strings(paths)
+		for _, importPath := range paths {
+			name := imports[importPath]
+			if name == path.Base(importPath) {
+				name = ""
+			}
+
+			fmt.Fprintf(buf, "%s %q\n", name, importPath)
+		}
+		fmt.Fprintln(buf, ")")
+		fmt.Fprintln(buf)
+	}
+
+	buf.Write(body.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+// exportedName converts the provided value to exported identifier.
+func exportedName(value string) string {
+	res := new(strings.Builder)
+	upper := true
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		res.WriteRune(r)
+	}
+
+	return res.String()
+}
+
+func uniqueName(name string, used map[string]bool) string {
+	res := name
+	for i := 2; used[res]; i++ {
+		res = fmt.Sprintf("%s%d", name, i)
+	}
+
+	used[res] = true
+	return res
+}
@@ -0,0 +1,22 @@
+module github.com/TsvetanMilanov/go-simple-di/cmd/di-gen
+
+go 1.22.0
+
+require (
+	github.com/TsvetanMilanov/go-simple-di v0.0.0
+	github.com/smartystreets/goconvey v1.6.4
+)
+
+require (
+	github.com/gopherjs/gopherjs v0.0.0-20181017120253-0766667cb4d1 // indirect
+	github.com/jtolds/gls v4.20.0+incompatible // indirect
+	github.com/smartystreets/assertions v0.0.0-20180927180507-b2de0cb4f26d // indirect
+)
+
+require (
+	golang.org/x/mod v0.22.0 // indirect
+	golang.org/x/sync v0.10.0 // indirect
+	golang.org/x/tools v0.28.0
+)
+
+replace github.com/TsvetanMilanov/go-simple-di => ../..
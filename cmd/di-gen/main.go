@@ -0,0 +1,58 @@
+// Command di-gen generates code which creates and wires the dependencies of
+// a package without reflection.
+//
+// The struct types annotated with di:register comment directive are
+// registered as pointer dependencies. The directive accepts the options of
+// the di struct tag, for example:
+//
+//	//di:register name=someName
+//	type named struct{}
+//
+// The marked fields of the registered types are wired with the same rules
+// which the di container uses at runtime. The generation fails when a marked
+// field cannot be wired or more than one registration can be injected in
+// interface field.
+//
+// Usage:
+//
+//	di-gen [flags] [package]
+//
+// The package defaults to the current directory. The generated file is
+// written in the directory of the package.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	cfg := new(config)
+	flag.StringVar(&cfg.output, "output", "di_gen.go", "name of the generated file")
+	flag.StringVar(&cfg.typeName, "type", "diGraph", "name of the generated type which holds the dependencies")
+	flag.StringVar(&cfg.funcName, "func", "newDIGraph", "name of the generated function which creates the dependencies")
+	flag.Parse()
+
+	pattern := "."
+	if flag.NArg() > 0 {
+		pattern = flag.Arg(0)
+	}
+
+	err := run(pattern, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "di-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(pattern string, cfg *config) error {
+	res, err := generate(pattern, cfg)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(res.dir, cfg.output), res.source, 0644)
+}
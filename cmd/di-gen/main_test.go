@@ -0,0 +1,97 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGenerate(t *testing.T) {
+	Convey("generate", t, func() {
+		cfg := &config{output: "di_gen.go", typeName: "diGraph", funcName: "newDIGraph"}
+
+		Convey("Should generate the wiring of the registered types.", func() {
+			res, err := generate("./testdata/basic", cfg)
+			So(err, ShouldBeNil)
+
+			expected, err := ioutil.ReadFile(filepath.Join("testdata", "basic", "di_gen.go.golden"))
+			So(err, ShouldBeNil)
+
+			So(string(res.source), ShouldEqual, string(expected))
+			abs, err := filepath.Abs(filepath.Join("testdata", "basic"))
+			So(err, ShouldBeNil)
+			So(res.dir, ShouldEqual, abs)
+		})
+		Convey("Should wire the fields of embedded structs.", func() {
+			res, err := generate("./testdata/embedded", cfg)
+			So(err, ShouldBeNil)
+
+			expected, err := ioutil.ReadFile(filepath.Join("testdata", "embedded", "di_gen.go.golden"))
+			So(err, ShouldBeNil)
+
+			So(string(res.source), ShouldEqual, string(expected))
+		})
+		Convey("Should import the packages with the same name with unique names.", func() {
+			res, err := generate("./testdata/imports", cfg)
+			So(err, ShouldBeNil)
+
+			expected, err := ioutil.ReadFile(filepath.Join("testdata", "imports", "di_gen.go.golden"))
+			So(err, ShouldBeNil)
+
+			So(string(res.source), ShouldEqual, string(expected))
+		})
+		Convey("Should use the configured names.", func() {
+			res, err := generate("./testdata/basic", &config{typeName: "graph", funcName: "wire"})
+
+			So(err, ShouldBeNil)
+			So(string(res.source), ShouldContainSubstring, "type graph struct {")
+			So(string(res.source), ShouldContainSubstring, "func wire() *graph {")
+		})
+		Convey("Should fail for fields which cannot be wired.", func() {
+			_, err := generate("./testdata/missing", cfg)
+
+			So(err, ShouldNotBeNil)
+			lines := strings.Split(err.Error(), "\n")
+			So(lines, ShouldHaveLength, 8)
+			So(lines[0], ShouldEndWith, "missing.go:5:2: [*root] unable to find registered dependency: Named")
+			So(lines[1], ShouldEndWith, "missing.go:6:2: [*root] unable to find registered dependency: W")
+			So(lines[2], ShouldEndWith, "missing.go:7:2: [*root] cannot set field unexp")
+			So(lines[3], ShouldEndWith, "missing.go:8:2: [*root] unable to find registered dependency: Value")
+			So(lines[4], ShouldEndWith, "missing.go:9:2: [*root] invalid di tag 'key' at position 1: unknown option 'key'")
+			So(lines[5], ShouldEndWith, "missing.go:10:2: [*root] unsupported di tag option 'lazy' for field Lazy")
+			So(lines[6], ShouldEndWith, "missing.go:16:2: [*root] cannot set field base.Dep")
+			So(lines[7], ShouldEndWith, "missing.go:12:2: [*root] cannot set field Struct")
+		})
+		Convey("Should fail for ambiguous interface fields.", func() {
+			_, err := generate("./testdata/ambiguous", cfg)
+
+			So(err, ShouldNotBeNil)
+			lines := strings.Split(err.Error(), "\n")
+			So(lines, ShouldHaveLength, 2)
+			So(lines[0], ShouldEndWith,
+				"ambiguous.go:5:2: [*root] ambiguous dependency: W can be satisfied by *first (name: first), *second (name: second)")
+			So(lines[1], ShouldEndWith,
+				"ambiguous.go:7:2: [*root] ambiguous dependency: Optional can be satisfied by *first (name: first), *second (name: second)")
+		})
+	})
+
+	Convey("run", t, func() {
+		Convey("Should write the generated file in the package directory.", func() {
+			output := filepath.Join("testdata", "basic", "wiring.go")
+			defer os.Remove(output)
+
+			err := run("./testdata/basic", &config{output: "wiring.go", typeName: "diGraph", funcName: "newDIGraph"})
+
+			So(err, ShouldBeNil)
+			res, err := ioutil.ReadFile(output)
+			So(err, ShouldBeNil)
+			expected, err := ioutil.ReadFile(filepath.Join("testdata", "basic", "di_gen.go.golden"))
+			So(err, ShouldBeNil)
+			So(string(res), ShouldEqual, string(expected))
+		})
+	})
+}
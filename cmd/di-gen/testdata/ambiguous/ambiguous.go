@@ -0,0 +1,22 @@
+package ambiguous
+
+//di:register
+type root struct {
+	W        worker `di:""`
+	Named    worker `di:"name=second"`
+	Optional worker `di:"optional"`
+}
+
+//di:register name=first
+type first struct{}
+
+func (f *first) Work() string { return "first" }
+
+//di:register name=second
+type second struct{}
+
+func (s *second) Work() string { return "second" }
+
+type worker interface {
+	Work() string
+}
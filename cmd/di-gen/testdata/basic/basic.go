@@ -0,0 +1,36 @@
+package basic
+
+//di:register
+type root struct {
+	Nested *nested `di:""`
+	Named  *named  `di:"name=someName"`
+	Other  string
+	Opt    worker `di:"optional,name=missing"`
+}
+
+//di:register
+type nested struct {
+	W worker `di:""`
+}
+
+//di:register name=someName
+type named struct {
+	name string
+}
+
+type worker interface {
+	Work() string
+}
+
+type (
+	//di:register
+	builder struct {
+		work string
+	}
+
+	notRegistered struct{}
+)
+
+func (b *builder) Work() string {
+	return b.work
+}
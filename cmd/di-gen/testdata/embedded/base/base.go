@@ -0,0 +1,11 @@
+package base
+
+// Service contains the dependencies shared by the services.
+type Service struct {
+	Logger Logger `di:""`
+}
+
+// Logger logs messages.
+type Logger interface {
+	Log(msg string)
+}
@@ -0,0 +1,26 @@
+package embedded
+
+import "github.com/TsvetanMilanov/go-simple-di/cmd/di-gen/testdata/embedded/base"
+
+//di:register
+type handler struct {
+	*base.Service
+	common
+	*Shared
+}
+
+type common struct {
+	Store *store `di:""`
+}
+
+type Shared struct {
+	Store *store `di:""`
+}
+
+//di:register
+type store struct{}
+
+//di:register
+type logger struct{}
+
+func (l *logger) Log(msg string) {}
@@ -0,0 +1,11 @@
+package config
+
+// Server contains the dependencies of the servers.
+type Server struct {
+	Store Store `di:""`
+}
+
+// Store returns the stored values.
+type Store interface {
+	Get(key string) string
+}
@@ -0,0 +1,11 @@
+package config
+
+// Client contains the dependencies of the clients.
+type Client struct {
+	Store Store `di:""`
+}
+
+// Store returns the stored values.
+type Store interface {
+	Get(key string) string
+}
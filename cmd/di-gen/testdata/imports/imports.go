@@ -0,0 +1,21 @@
+package imports
+
+import (
+	"github.com/TsvetanMilanov/go-simple-di/cmd/di-gen/testdata/imports/a/config"
+	clientconfig "github.com/TsvetanMilanov/go-simple-di/cmd/di-gen/testdata/imports/b/config"
+)
+
+//di:register
+type server struct {
+	*config.Server
+}
+
+//di:register
+type client struct {
+	*clientconfig.Client
+}
+
+//di:register
+type store struct{}
+
+func (s *store) Get(key string) string { return key }
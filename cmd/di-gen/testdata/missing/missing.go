@@ -0,0 +1,24 @@
+package missing
+
+//di:register
+type root struct {
+	Named  *dep   `di:"name=missing"`
+	W      worker `di:""`
+	unexp  *dep   `di:""`
+	Value  int    `di:""`
+	Broken *dep   `di:"key"`
+	Lazy   *dep   `di:"lazy"`
+	*base
+	Struct dep `di:""`
+}
+
+type base struct {
+	Dep *dep `di:""`
+}
+
+//di:register
+type dep struct{}
+
+type worker interface {
+	Work() string
+}
@@ -0,0 +1,14 @@
+// Command ditag checks the di struct tags. It can be used as standalone
+// checker or with go vet:
+//
+//	go vet -vettool=$(which ditag) ./...
+package main
+
+import (
+	"github.com/TsvetanMilanov/go-simple-di/di/analysis/ditag"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(ditag.Analyzer)
+}
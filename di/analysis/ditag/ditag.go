@@ -0,0 +1,266 @@
+// Package ditag defines an Analyzer which checks the di struct tags.
+//
+// The analyzer reports the mistakes which the di container reports only
+// at runtime:
+//   - di tags which cannot be parsed;
+//   - di tag options which are not supported or cannot be combined;
+//   - di tags on unexported fields, which the container cannot set;
+//   - di tags on struct value fields, which the container cannot inject.
+package ditag
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/TsvetanMilanov/go-simple-di/internal/tags"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const doc = `check di struct tags
+
+The ditag analyzer reports di struct tags which the di container rejects at
+runtime: malformed tags, unsupported or conflicting options, tags on
+unexported fields and tags on struct value fields.`
+
+// Analyzer checks the di struct tags.
+var Analyzer = &analysis.Analyzer{
+	Name:     "ditag",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{(*ast.StructType)(nil)}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		st := n.(*ast.StructType)
+		for _, field := range st.Fields.List {
+			if field.Tag != nil {
+				checkField(pass, st, field)
+			}
+		}
+	})
+
+	return nil, nil
+}
+
+func checkField(pass *analysis.Pass, st *ast.StructType, field *ast.Field) {
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return
+	}
+
+	if _, ok := reflect.StructTag(tag).Lookup(tags.Key); !ok {
+		return
+	}
+
+	parsed, err := tags.Lookup(reflect.StructTag(tag))
+	if err != nil {
+		pass.Reportf(errorPos(field.Tag, err), "malformed di tag: %s", err.Error())
+		return
+	}
+
+	err = parsed.Validate()
+	if err != nil {
+		pass.Reportf(field.Tag.Pos(), "invalid di tag: %s", err.Error())
+		return
+	}
+
+	t := pass.TypesInfo.TypeOf(field.Type)
+	if !isValidType(t) {
+		pass.Report(analysis.Diagnostic{
+			Pos:     field.Tag.Pos(),
+			End:     field.Tag.End(),
+			Message: fmt.Sprintf("di tag on field %s of type %s: struct values cannot be injected, use pointer", fieldName(field), t),
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message:   "Remove the di tag",
+				TextEdits: []analysis.TextEdit{removeTagEdit(field, tag)},
+			}},
+		})
+		return
+	}
+
+	for _, name := range fieldNames(field) {
+		if name.IsExported() {
+			continue
+		}
+
+		diag := analysis.Diagnostic{
+			Pos:     name.Pos(),
+			End:     name.End(),
+			Message: fmt.Sprintf("di tag on unexported field %s: the di container cannot set it", name.Name),
+		}
+		if fix, ok := exportFix(pass, st, field, name); ok {
+			diag.SuggestedFixes = []analysis.SuggestedFix{fix}
+		}
+
+		pass.Report(diag)
+	}
+}
+
+// errorPos returns the position of the invalid character in the di tag.
+// It falls back to the position of the struct tag when the position cannot
+// be mapped to the source, for example in interpreted string literals.
+func errorPos(lit *ast.BasicLit, err error) token.Pos {
+	syntaxErr, ok := err.(*tags.SyntaxError)
+	if !ok || !strings.HasPrefix(lit.Value, "`") {
+		return lit.Pos()
+	}
+
+	prefix := tags.Key + ":\""
+	for i := strings.Index(lit.Value, prefix); i >= 0; {
+		if lit.Value[i-1] == '`' || lit.Value[i-1] == ' ' {
+			return lit.Pos() + token.Pos(i+len(prefix)+syntaxErr.Pos-1)
+		}
+
+		next := strings.Index(lit.Value[i+1:], prefix)
+		if next < 0 {
+			break
+		}
+
+		i += next + 1
+	}
+
+	return lit.Pos()
+}
+
+// isValidType checks if the di container can inject the type. Only struct
+// values cannot be injected.
+func isValidType(t types.Type) bool {
+	if t == nil {
+		return true
+	}
+
+	_, isStruct := t.Underlying().(*types.Struct)
+	return !isStruct
+}
+
+// fieldNames returns the names of the field. The name of embedded field is
+// the identifier of its type.
+func fieldNames(field *ast.Field) []*ast.Ident {
+	if len(field.Names) > 0 {
+		return field.Names
+	}
+
+	t := field.Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+
+	switch t := t.(type) {
+	case *ast.Ident:
+		return []*ast.Ident{t}
+	case *ast.SelectorExpr:
+		return []*ast.Ident{t.Sel}
+	}
+
+	return nil
+}
+
+func fieldName(field *ast.Field) string {
+	names := []string{}
+	for _, name := range fieldNames(field) {
+		names = append(names, name.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// exportFix renames the unexported field and all its uses in the package.
+// Embedded fields are not renamed, because their names are the names of
+// their types.
+func exportFix(pass *analysis.Pass, st *ast.StructType, field *ast.Field, name *ast.Ident) (analysis.SuggestedFix, bool) {
+	obj := pass.TypesInfo.Defs[name]
+	structType, ok := pass.TypesInfo.TypeOf(st).(*types.Struct)
+	if obj == nil || !ok || len(field.Names) == 0 {
+		return analysis.SuggestedFix{}, false
+	}
+
+	r, size := utf8.DecodeRuneInString(name.Name)
+	if !unicode.IsLetter(r) || unicode.IsUpper(r) {
+		return analysis.SuggestedFix{}, false
+	}
+
+	exported := string(unicode.ToUpper(r)) + name.Name[size:]
+	for i := 0; i < structType.NumFields(); i++ {
+		if structType.Field(i).Name() == exported {
+			// Renaming would conflict with existing field.
+			return analysis.SuggestedFix{}, false
+		}
+	}
+
+	edits := []analysis.TextEdit{{Pos: name.Pos(), End: name.End(), NewText: []byte(exported)}}
+	for ident, use := range pass.TypesInfo.Uses {
+		if use == obj {
+			edits = append(edits, analysis.TextEdit{Pos: ident.Pos(), End: ident.End(), NewText: []byte(exported)})
+		}
+	}
+
+	return analysis.SuggestedFix{
+		Message:   fmt.Sprintf("Rename %s to %s", name.Name, exported),
+		TextEdits: edits,
+	}, true
+}
+
+// removeTagEdit removes the di tag from the struct tag of the field. The
+// whole struct tag is removed if it contains only the di tag.
+func removeTagEdit(field *ast.Field, tag string) analysis.TextEdit {
+	rest := []string{}
+	for _, part := range splitTag(tag) {
+		if !strings.HasPrefix(part, tags.Key+":") {
+			rest = append(rest, part)
+		}
+	}
+
+	if len(rest) == 0 {
+		// Remove the space between the type and the tag as well.
+		return analysis.TextEdit{Pos: field.Type.End(), End: field.Tag.End()}
+	}
+
+	return analysis.TextEdit{
+		Pos:     field.Tag.Pos(),
+		End:     field.Tag.End(),
+		NewText: []byte("`" + strings.Join(rest, " ") + "`"),
+	}
+}
+
+// splitTag splits conventional struct tag to its key:"value" pairs.
+func splitTag(tag string) []string {
+	res := []string{}
+	for {
+		tag = strings.TrimLeft(tag, " ")
+		if len(tag) == 0 {
+			return res
+		}
+
+		i := strings.Index(tag, ":\"")
+		if i < 0 {
+			return append(res, tag)
+		}
+
+		end := i + 2
+		for end < len(tag) && tag[end] != '"' {
+			if tag[end] == '\\' {
+				end++
+			}
+			end++
+		}
+
+		if end >= len(tag) {
+			return append(res, tag)
+		}
+
+		res = append(res, tag[:end+1])
+		tag = tag[end+1:]
+	}
+}
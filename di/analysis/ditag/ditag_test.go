@@ -0,0 +1,12 @@
+package ditag_test
+
+import (
+	"testing"
+
+	"github.com/TsvetanMilanov/go-simple-di/di/analysis/ditag"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), ditag.Analyzer, "a")
+}
@@ -0,0 +1,42 @@
+package a
+
+type dep struct{}
+
+type worker interface {
+	Work() string
+}
+
+type base struct{}
+
+type valid struct {
+	Ptr       *dep     `di:""`
+	Named     *dep     `di:"name=named"`
+	Interface worker   `json:"interface" di:""`
+	Port      int      `di:"name=port"`
+	Hosts     []string `di:"name=hosts"`
+	NotMarked int
+	Other     int `json:"other"`
+}
+
+type invalid struct {
+	Malformed *dep    `di:"name"`            // want `malformed di tag: invalid di tag 'name' at position 5: option 'name' requires value`
+	Unknown   *dep    `di:"key=value"`       // want `malformed di tag: invalid di tag 'key=value' at position 1: unknown option 'key'`
+	unexp     *dep    `di:""`                // want `di tag on unexported field unexp: the di container cannot set it`
+	Struct    dep     `json:"s" di:"name=x"` // want `di tag on field Struct of type a.dep: struct values cannot be injected, use pointer`
+	conflict  *dep    `di:""`                // want `di tag on unexported field conflict: the di container cannot set it`
+	Conflict  worker  `di:""`
+	*base     `di:""` // want `di tag on unexported field base: the di container cannot set it`
+}
+
+type invalidOptions struct {
+	Lazy     *dep   `di:"lazy"`            // want `invalid di tag: unsupported di tag option 'lazy'`
+	Group    *dep   `di:"group=handlers"`  // want `invalid di tag: unsupported di tag option 'group'`
+	Default  *dep   `di:"new,default=x"`   // want `invalid di tag: di tag option 'default' cannot be used with 'new' or 'optional'`
+	EnvName  string `di:"env=PORT,name=x"` // want `invalid di tag: di tag option 'env' cannot be used with 'name' or 'new'`
+	Required *dep   `di:"required"`        // want `invalid di tag: di tag option 'required' can be used only with 'env' or 'config'`
+	Env      string `di:"env=PORT,default=80,required"`
+}
+
+func use(i *invalid) *dep {
+	return i.unexp
+}
@@ -0,0 +1,13 @@
+module github.com/TsvetanMilanov/go-simple-di/di/analysis
+
+go 1.22.0
+
+require github.com/TsvetanMilanov/go-simple-di v0.0.0
+
+require (
+	golang.org/x/mod v0.22.0 // indirect
+	golang.org/x/sync v0.10.0 // indirect
+	golang.org/x/tools v0.28.0
+)
+
+replace github.com/TsvetanMilanov/go-simple-di => ../..
@@ -0,0 +1,81 @@
+package di
+
+import (
+	"strconv"
+	"testing"
+)
+
+func newBenchmarkContainer(b *testing.B) *Container {
+	c := NewContainer()
+	err := c.Register(
+		&Dependency{Value: new(firstLevelDependency)},
+		&Dependency{Value: new(secondLevelDependency)},
+		&Dependency{Value: new(pointerDependency)},
+		&Dependency{Value: new(builder)},
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return c
+}
+
+func BenchmarkResolveNew(b *testing.B) {
+	c := newBenchmarkContainer(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := c.ResolveNew(new(rootDependency)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResolveNewRegistered(b *testing.B) {
+	c := newBenchmarkContainer(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := c.ResolveNew(new(secondLevelDependency)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResolveAll(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		c := newBenchmarkContainer(b)
+		b.StartTimer()
+		if err := c.ResolveAll(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResolveInterfaceLargeContainer(b *testing.B) {
+	c := NewContainer()
+	for i := 0; i < 5000; i++ {
+		err := c.Register(&Dependency{Value: new(pointerDependency), Name: strconv.Itoa(i)})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	err := c.Register(
+		&Dependency{Value: new(pointerDependency)},
+		&Dependency{Value: new(builder)},
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := c.ResolveNew(new(secondLevelDependency)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
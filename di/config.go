@@ -0,0 +1,59 @@
+package di
+
+import (
+	"fmt"
+	"os"
+)
+
+// ConfigSource provides the values injected in the fields marked with the
+// config option. It is implemented by *config.Config.
+type ConfigSource interface {
+	// Lookup returns the value with the provided dot separated key.
+	Lookup(key string) (interface{}, bool)
+}
+
+// SetConfig sets the source of the values injected in the fields marked
+// with the config option.
+func (c *Container) SetConfig(source ConfigSource) {
+	c.config = source
+}
+
+// injectValue sets the field marked with the config or env option to the
+// value of its key converted to the field type. The default value is used
+// when the key is missing. The missing environment variables are ignored
+// unless the field is marked as required, and the missing config keys are
+// ignored if the field is marked as optional.
+func (c *Container) injectValue(d *dependencyMetadata, f markedField) error {
+	var value interface{}
+	found := false
+	var source string
+	if len(f.tags.Env) > 0 {
+		source = fmt.Sprintf("environment variable '%s'", f.tags.Env)
+		value, found = os.LookupEnv(f.tags.Env)
+	} else {
+		source = fmt.Sprintf("config key '%s'", f.tags.Config)
+		if c.config != nil {
+			value, found = c.config.Lookup(f.tags.Config)
+		}
+	}
+
+	if !found && f.tags.HasDefault {
+		value, found = f.tags.Default, true
+	}
+
+	if !found {
+		if f.tags.Optional || (len(f.tags.Env) > 0 && !f.tags.Required) {
+			return nil
+		}
+
+		return fmt.Errorf("missing %s for field %s", source, f.name)
+	}
+
+	v, err := convertValue(value, f.field.Type)
+	if err != nil {
+		return fmt.Errorf("cannot convert %s for field %s: %s", source, f.name, err.Error())
+	}
+
+	fieldByIndex(d.valueElem, f.index).Set(v)
+	return nil
+}
@@ -0,0 +1,151 @@
+// Package config loads layered configuration which can be injected by the
+// di container in the fields marked with the config option:
+//
+//	cfg, err := config.New(
+//		config.File("config.json"),
+//		config.Env("APP"),
+//		config.Flags(flag.CommandLine),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//
+//	c := di.NewContainer()
+//	c.SetConfig(cfg)
+//
+//	type db struct {
+//		DSN string `di:"config=db.dsn"`
+//	}
+//
+// The keys are dot separated paths in the nested configuration values.
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Source loads one layer of the configuration. The keys of the result can
+// be nested maps or dot separated paths.
+type Source interface {
+	Load() (map[string]interface{}, error)
+}
+
+// SourceFunc is function which implements Source.
+type SourceFunc func() (map[string]interface{}, error)
+
+// Load implements Source.
+func (f SourceFunc) Load() (map[string]interface{}, error) {
+	return f()
+}
+
+// Config contains the merged values of the configuration sources.
+type Config struct {
+	values map[string]interface{}
+}
+
+// New loads the provided sources in order and merges their values. The
+// values of the later sources override the values of the earlier ones.
+func New(sources ...Source) (*Config, error) {
+	c := &Config{values: map[string]interface{}{}}
+	for _, s := range sources {
+		values, err := s.Load()
+		if err != nil {
+			return nil, err
+		}
+
+		for k, v := range values {
+			err = setValue(c.values, k, v)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// Lookup returns the value with the provided dot separated key. The value
+// of key which has nested keys is map[string]interface{}.
+func (c *Config) Lookup(key string) (interface{}, bool) {
+	var res interface{} = c.values
+	for _, part := range strings.Split(key, ".") {
+		m, ok := res.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		res, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return res, true
+}
+
+// setValue merges the value in the provided dot separated key of m.
+func setValue(m map[string]interface{}, key string, value interface{}) error {
+	parts := strings.Split(key, ".")
+	for i, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]interface{})
+		if !ok {
+			if _, exists := m[part]; exists {
+				return fmt.Errorf("config key %s is not object", strings.Join(parts[:i+1], "."))
+			}
+
+			next = map[string]interface{}{}
+			m[part] = next
+		}
+
+		m = next
+	}
+
+	last := parts[len(parts)-1]
+	value = normalize(value)
+	if nested, ok := value.(map[string]interface{}); ok {
+		if existing, ok := m[last].(map[string]interface{}); ok {
+			for k, v := range nested {
+				err := setValue(existing, k, v)
+				if err != nil {
+					return fmt.Errorf("config key %s: %s", key, err.Error())
+				}
+			}
+
+			return nil
+		}
+	}
+
+	m[last] = value
+	return nil
+}
+
+// normalize converts the map[interface{}]interface{} values which some
+// YAML decoders produce to map[string]interface{}.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			res[fmt.Sprint(k)] = normalize(item)
+		}
+
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			res[k] = normalize(item)
+		}
+
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, item := range v {
+			res[i] = normalize(item)
+		}
+
+		return res
+	}
+
+	return value
+}
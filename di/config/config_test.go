@@ -0,0 +1,180 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConfig(t *testing.T) {
+	Convey("Config", t, func() {
+		Convey("Lookup", func() {
+			c, err := New(Map(map[string]interface{}{
+				"db": map[string]interface{}{
+					"dsn":  "postgres://localhost",
+					"pool": map[string]interface{}{"size": 5},
+				},
+				"http.port": 8080,
+			}))
+			So(err, ShouldBeNil)
+
+			Convey("Should return the values with dot separated keys.", func() {
+				v, ok := c.Lookup("db.dsn")
+				So(ok, ShouldBeTrue)
+				So(v, ShouldEqual, "postgres://localhost")
+
+				v, ok = c.Lookup("db.pool.size")
+				So(ok, ShouldBeTrue)
+				So(v, ShouldEqual, 5)
+
+				v, ok = c.Lookup("http.port")
+				So(ok, ShouldBeTrue)
+				So(v, ShouldEqual, 8080)
+			})
+			Convey("Should return the nested values as maps.", func() {
+				v, ok := c.Lookup("db.pool")
+				So(ok, ShouldBeTrue)
+				So(v, ShouldResemble, map[string]interface{}{"size": 5})
+			})
+			Convey("Should report the missing keys.", func() {
+				_, ok := c.Lookup("db.user")
+				So(ok, ShouldBeFalse)
+
+				_, ok = c.Lookup("db.dsn.host")
+				So(ok, ShouldBeFalse)
+			})
+		})
+		Convey("New", func() {
+			Convey("Should override the values of the earlier sources.", func() {
+				c, err := New(
+					Map(map[string]interface{}{
+						"db": map[string]interface{}{"dsn": "file", "user": "admin"},
+					}),
+					Map(map[string]interface{}{"db.dsn": "env"}),
+				)
+
+				So(err, ShouldBeNil)
+				v, _ := c.Lookup("db.dsn")
+				So(v, ShouldEqual, "env")
+				v, _ = c.Lookup("db.user")
+				So(v, ShouldEqual, "admin")
+			})
+			Convey("Should normalize maps with interface keys.", func() {
+				c, err := New(Map(map[string]interface{}{
+					"db": map[interface{}]interface{}{"dsn": "yaml"},
+				}))
+
+				So(err, ShouldBeNil)
+				v, _ := c.Lookup("db.dsn")
+				So(v, ShouldEqual, "yaml")
+			})
+			Convey("Should return error for nested key of value.", func() {
+				_, err := New(
+					Map(map[string]interface{}{"db": "value"}),
+					Map(map[string]interface{}{"db.dsn": "env"}),
+				)
+
+				So(err, ShouldBeError, "config key db is not object")
+			})
+			Convey("Should return the source errors.", func() {
+				_, err := New(SourceFunc(func() (map[string]interface{}, error) {
+					return nil, errors.New("source error")
+				}))
+
+				So(err, ShouldBeError, "source error")
+			})
+		})
+	})
+
+	Convey("Sources", t, func() {
+		dir, err := ioutil.TempDir("", "config")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		write := func(name, content string) string {
+			path := filepath.Join(dir, name)
+			So(ioutil.WriteFile(path, []byte(content), 0644), ShouldBeNil)
+			return path
+		}
+
+		Convey("File", func() {
+			Convey("Should load JSON files.", func() {
+				path := write("config.json", `{"db": {"dsn": "json"}}`)
+				c, err := New(File(path))
+
+				So(err, ShouldBeNil)
+				v, _ := c.Lookup("db.dsn")
+				So(v, ShouldEqual, "json")
+			})
+			Convey("Should return error for other formats.", func() {
+				path := write("config.yaml", "db: {}")
+				_, err := New(File(path))
+
+				So(err, ShouldBeError, "unsupported config file "+path+", use FileWith with unmarshaler for the format")
+			})
+			Convey("Should return decode errors.", func() {
+				path := write("invalid.json", `{`)
+				_, err := New(File(path))
+
+				So(err, ShouldBeError, "unable to decode config file "+path+": unexpected end of JSON input")
+			})
+			Convey("Should return read errors.", func() {
+				_, err := New(File(filepath.Join(dir, "missing.json")))
+
+				So(err, ShouldNotBeNil)
+				So(os.IsNotExist(err), ShouldBeTrue)
+			})
+		})
+		Convey("FileWith", func() {
+			Convey("Should decode the file with the unmarshaler.", func() {
+				path := write("config.txt", "ignored")
+				unmarshal := func(data []byte, v interface{}) error {
+					*(v.(*map[string]interface{})) = map[string]interface{}{"format": string(data)}
+					return nil
+				}
+				c, err := New(FileWith(path, unmarshal))
+
+				So(err, ShouldBeNil)
+				v, _ := c.Lookup("format")
+				So(v, ShouldEqual, "ignored")
+			})
+		})
+		Convey("Env", func() {
+			Convey("Should load the variables with the prefix.", func() {
+				os.Setenv("DICONFIGTEST_DB__MAX_CONNS", "10")
+				os.Setenv("DICONFIGTEST_PORT", "8080")
+				defer os.Unsetenv("DICONFIGTEST_DB__MAX_CONNS")
+				defer os.Unsetenv("DICONFIGTEST_PORT")
+
+				c, err := New(Env("DICONFIGTEST"))
+
+				So(err, ShouldBeNil)
+				v, _ := c.Lookup("db.max_conns")
+				So(v, ShouldEqual, "10")
+				v, _ = c.Lookup("port")
+				So(v, ShouldEqual, "8080")
+			})
+		})
+		Convey("Flags", func() {
+			Convey("Should load only the set flags.", func() {
+				fs := flag.NewFlagSet("test", flag.ContinueOnError)
+				fs.String("db.dsn", "default", "")
+				fs.Int("http.port", 80, "")
+				So(fs.Parse([]string{"-http.port=8080"}), ShouldBeNil)
+
+				c, err := New(Flags(fs))
+
+				So(err, ShouldBeNil)
+				v, _ := c.Lookup("http.port")
+				So(v, ShouldEqual, 8080)
+				_, ok := c.Lookup("db.dsn")
+				So(ok, ShouldBeFalse)
+			})
+		})
+	})
+}
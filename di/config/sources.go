@@ -0,0 +1,104 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Unmarshaler decodes configuration file, e.g. json.Unmarshal or the
+// Unmarshal function of YAML or TOML package.
+type Unmarshaler func(data []byte, v interface{}) error
+
+// File loads JSON configuration file. Use FileWith for the other formats.
+func File(path string) Source {
+	if strings.ToLower(filepath.Ext(path)) != ".json" {
+		return SourceFunc(func() (map[string]interface{}, error) {
+			return nil, fmt.Errorf("unsupported config file %s, use FileWith with unmarshaler for the format", path)
+		})
+	}
+
+	return FileWith(path, json.Unmarshal)
+}
+
+// FileWith loads configuration file decoded with the provided unmarshaler.
+// This allows loading YAML or TOML files without adding dependencies to
+// this package:
+//
+//	config.FileWith("config.yaml", yaml.Unmarshal)
+func FileWith(path string, unmarshal Unmarshaler) Source {
+	return SourceFunc(func() (map[string]interface{}, error) {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		res := map[string]interface{}{}
+		err = unmarshal(data, &res)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode config file %s: %s", path, err.Error())
+		}
+
+		return res, nil
+	})
+}
+
+// Map returns source with the provided values.
+func Map(values map[string]interface{}) Source {
+	return SourceFunc(func() (map[string]interface{}, error) {
+		return values, nil
+	})
+}
+
+// Env loads the environment variables with the provided prefix. The prefix
+// and the following underscore are removed from the variable names, the
+// names are lowercased and double underscores separate the nested keys.
+// For example APP_DB__MAX_CONNS with prefix APP is loaded as db.max_conns.
+func Env(prefix string) Source {
+	return SourceFunc(func() (map[string]interface{}, error) {
+		res := map[string]interface{}{}
+		namePrefix := prefix
+		if len(namePrefix) > 0 {
+			namePrefix += "_"
+		}
+
+		for _, env := range os.Environ() {
+			i := strings.Index(env, "=")
+			if i < 0 || !strings.HasPrefix(env[:i], namePrefix) {
+				continue
+			}
+
+			name := strings.ToLower(env[len(namePrefix):i])
+			if len(name) == 0 {
+				continue
+			}
+
+			res[strings.Replace(name, "__", ".", -1)] = env[i+1:]
+		}
+
+		return res, nil
+	})
+}
+
+// Flags loads the flags which are set on the command line. The flag names
+// are the keys, e.g. -db.dsn sets db.dsn. The default values of the flags
+// are not loaded, so they do not override the earlier sources.
+func Flags(fs *flag.FlagSet) Source {
+	return SourceFunc(func() (map[string]interface{}, error) {
+		res := map[string]interface{}{}
+		fs.Visit(func(f *flag.Flag) {
+			if getter, ok := f.Value.(flag.Getter); ok {
+				res[f.Name] = getter.Get()
+				return
+			}
+
+			res[f.Name] = f.Value.String()
+		})
+
+		return res, nil
+	})
+}
@@ -0,0 +1,151 @@
+package di
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type mapConfig map[string]interface{}
+
+func (m mapConfig) Lookup(key string) (interface{}, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+type dbSettings struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func TestConfig(t *testing.T) {
+	Convey("Config", t, func() {
+		type db struct {
+			DSN      string        `di:"config=db.dsn"`
+			MaxConns int           `di:"config=db.max_conns"`
+			Timeout  time.Duration `di:"config=db.timeout"`
+			Replicas []string      `di:"config=db.replicas,optional"`
+			Settings *dbSettings   `di:"config=db.settings,optional"`
+		}
+
+		Convey("Should inject the converted config values.", func() {
+			c := NewContainer()
+			c.SetConfig(mapConfig{
+				"db.dsn":       "postgres://localhost",
+				"db.max_conns": "10",
+				"db.timeout":   "5s",
+				"db.replicas":  []interface{}{"a", "b"},
+				"db.settings":  map[string]interface{}{"host": "localhost", "port": float64(5432)},
+			})
+			res := new(db)
+			err := c.Inject(res)
+
+			So(err, ShouldBeNil)
+			So(res.DSN, ShouldEqual, "postgres://localhost")
+			So(res.MaxConns, ShouldEqual, 10)
+			So(res.Timeout, ShouldEqual, 5*time.Second)
+			So(res.Replicas, ShouldResemble, []string{"a", "b"})
+			So(res.Settings, ShouldResemble, &dbSettings{Host: "localhost", Port: 5432})
+		})
+		Convey("Should inject config values in registered dependencies.", func() {
+			c := NewContainer()
+			c.SetConfig(mapConfig{"db.dsn": "dsn", "db.max_conns": 1, "db.timeout": "1s"})
+			err := c.Register(&Dependency{Value: new(db)})
+			So(err, ShouldBeNil)
+
+			res := new(db)
+			err = c.Resolve(res)
+
+			So(err, ShouldBeNil)
+			So(res.DSN, ShouldEqual, "dsn")
+			So(res.Replicas, ShouldBeNil)
+		})
+		Convey("Should return error for missing config key.", func() {
+			c := NewContainer()
+			c.SetConfig(mapConfig{})
+			err := c.Inject(new(db))
+
+			So(err, ShouldBeError, "[*"+pkgPath+".db] missing config key 'db.dsn' for field DSN")
+		})
+		Convey("Should return error for missing config source.", func() {
+			c := NewContainer()
+			err := c.Inject(new(db))
+
+			So(err, ShouldBeError, "[*"+pkgPath+".db] missing config key 'db.dsn' for field DSN")
+		})
+		Convey("Should return conversion errors.", func() {
+			c := NewContainer()
+			c.SetConfig(mapConfig{"db.dsn": "dsn", "db.max_conns": "many"})
+			err := c.Inject(new(db))
+
+			So(err, ShouldBeError, "[*"+pkgPath+".db] cannot convert config key 'db.max_conns' for field MaxConns: cannot parse 'many' as int")
+		})
+		Convey("Should not consider config fields dependencies.", func() {
+			type withConfig struct {
+				Value interface{} `di:"config=value,optional"`
+			}
+
+			c := NewContainer()
+			err := c.Register(&Dependency{Value: new(withConfig)}, &Dependency{Value: "unused"})
+			So(err, ShouldBeNil)
+
+			So(c.Usage().Unused, ShouldHaveLength, 2)
+			So(c.requiredInterfaces(), ShouldBeEmpty)
+		})
+		Convey("Should use the default value for missing config key.", func() {
+			type withDefault struct {
+				Timeout time.Duration `di:"config=timeout,default=5s"`
+			}
+
+			c := NewContainer()
+			c.SetConfig(mapConfig{})
+			res := new(withDefault)
+			err := c.Inject(res)
+
+			So(err, ShouldBeNil)
+			So(res.Timeout, ShouldEqual, 5*time.Second)
+		})
+		Convey("Should validate the options used with", func() {
+			testCases := map[string]struct {
+				value         interface{}
+				expectedError string
+			}{
+				"config and name.": {
+					value: &struct {
+						Value string `di:"config=value,name=value"`
+					}{},
+					expectedError: "di tag option 'config' cannot be used with 'name' or 'new' for field Value",
+				},
+				"env and new.": {
+					value: &struct {
+						Value *string `di:"env=VALUE,new"`
+					}{},
+					expectedError: "di tag option 'env' cannot be used with 'name' or 'new' for field Value",
+				},
+				"env and config.": {
+					value: &struct {
+						Value string `di:"env=VALUE,config=value"`
+					}{},
+					expectedError: "di tag options 'env' and 'config' cannot be used together for field Value",
+				},
+				"required.": {
+					value: &struct {
+						Value *string `di:"required"`
+					}{},
+					expectedError: "di tag option 'required' can be used only with 'env' or 'config' for field Value",
+				},
+			}
+
+			for testName, tc := range testCases {
+				Convey(testName, func() {
+					c := NewContainer()
+					err := c.Inject(tc.value)
+
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldEndWith, "] "+tc.expectedError)
+				})
+			}
+		})
+	})
+}
@@ -0,0 +1,147 @@
+package di
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// convertValue converts the configuration value to the provided type.
+// Strings are parsed for numbers, booleans and durations, and comma
+// separated strings are split for slices. Numbers are converted if the
+// conversion does not lose precision. The other values are converted
+// through JSON, e.g. maps to pointers to structs.
+func convertValue(value interface{}, t reflect.Type) (reflect.Value, error) {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return reflect.Zero(t), nil
+	}
+
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+
+	if s, ok := value.(string); ok {
+		return parseValue(s, t)
+	}
+
+	if res, ok := convertNumber(v, t); ok {
+		return res, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	res := reflect.New(t)
+	err = json.Unmarshal(data, res.Interface())
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("cannot convert %v to %s", value, t.String())
+	}
+
+	return res.Elem(), nil
+}
+
+// parseValue parses the string to value of the provided type.
+func parseValue(s string, t reflect.Type) (reflect.Value, error) {
+	res := reflect.New(t).Elem()
+	var err error
+	switch {
+	case t == durationType:
+		var d time.Duration
+		d, err = time.ParseDuration(s)
+		res.SetInt(int64(d))
+	case t.Kind() == reflect.String:
+		res.SetString(s)
+	case t.Kind() == reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		res.SetBool(b)
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(s, 0, t.Bits())
+		res.SetInt(i)
+	case isUnsigned(t.Kind()):
+		var u uint64
+		u, err = strconv.ParseUint(s, 0, t.Bits())
+		res.SetUint(u)
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(s, t.Bits())
+		res.SetFloat(f)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		parts := []string{}
+		if len(s) > 0 {
+			parts = strings.Split(s, ",")
+		}
+
+		res = reflect.MakeSlice(t, len(parts), len(parts))
+		for i, part := range parts {
+			var item reflect.Value
+			item, err = parseValue(strings.TrimSpace(part), t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			res.Index(i).Set(item)
+		}
+	default:
+		err = json.Unmarshal([]byte(s), res.Addr().Interface())
+	}
+
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("cannot parse '%s' as %s", s, t.String())
+	}
+
+	return res, nil
+}
+
+// convertNumber converts numbers between the numeric types. It does not
+// convert numbers which change when converted, e.g. numbers which do not fit
+// in the type or floats with fractions to integers.
+func convertNumber(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	if !isNumber(v.Kind()) || !isNumber(t.Kind()) {
+		return reflect.Value{}, false
+	}
+
+	if isUnsigned(t.Kind()) && isNegative(v) {
+		return reflect.Value{}, false
+	}
+
+	res := v.Convert(t)
+	back := res.Convert(v.Type())
+	if back.Interface() != v.Interface() {
+		return reflect.Value{}, false
+	}
+
+	return res, true
+}
+
+func isNumber(k reflect.Kind) bool {
+	return (k >= reflect.Int && k <= reflect.Uintptr) || isFloat(k)
+}
+
+func isUnsigned(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func isNegative(v reflect.Value) bool {
+	switch {
+	case isFloat(v.Kind()):
+		return v.Float() < 0
+	case isUnsigned(v.Kind()):
+		return false
+	}
+
+	return v.Int() < 0
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
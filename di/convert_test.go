@@ -0,0 +1,71 @@
+package di
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConvertValue(t *testing.T) {
+	Convey("convertValue", t, func() {
+		type kind string
+		testCases := map[string]struct {
+			value    interface{}
+			t        reflect.Type
+			expected interface{}
+		}{
+			"assignable value":     {value: 5, t: reflect.TypeOf(0), expected: 5},
+			"nil":                  {value: nil, t: reflect.TypeOf(""), expected: ""},
+			"interface":            {value: 5, t: reflect.TypeOf(new(interface{})).Elem(), expected: 5},
+			"string":               {value: "value", t: reflect.TypeOf(kind("")), expected: kind("value")},
+			"bool":                 {value: "true", t: reflect.TypeOf(false), expected: true},
+			"int":                  {value: "0x10", t: reflect.TypeOf(int8(0)), expected: int8(16)},
+			"uint":                 {value: "10", t: reflect.TypeOf(uint(0)), expected: uint(10)},
+			"float":                {value: "1.5", t: reflect.TypeOf(float32(0)), expected: float32(1.5)},
+			"duration":             {value: "1m", t: reflect.TypeOf(time.Duration(0)), expected: time.Minute},
+			"string slice":         {value: "a, b", t: reflect.TypeOf([]string{}), expected: []string{"a", "b"}},
+			"int slice":            {value: "1,2", t: reflect.TypeOf([]int{}), expected: []int{1, 2}},
+			"empty slice":          {value: "", t: reflect.TypeOf([]int{}), expected: []int{}},
+			"JSON string":          {value: `{"a":1}`, t: reflect.TypeOf(map[string]int{}), expected: map[string]int{"a": 1}},
+			"float to int":         {value: float64(10), t: reflect.TypeOf(0), expected: 10},
+			"int to duration":      {value: int64(time.Second), t: reflect.TypeOf(time.Duration(0)), expected: time.Second},
+			"slice through JSON":   {value: []interface{}{1.0, 2.0}, t: reflect.TypeOf([]int{}), expected: []int{1, 2}},
+			"map to pointer":       {value: map[string]interface{}{"port": 1.0}, t: reflect.TypeOf(new(dbSettings)), expected: &dbSettings{Port: 1}},
+			"float to float32":     {value: 0.5, t: reflect.TypeOf(float32(0)), expected: float32(0.5)},
+			"uint to int":          {value: uint8(5), t: reflect.TypeOf(0), expected: 5},
+			"positive int to uint": {value: 5, t: reflect.TypeOf(uint(0)), expected: uint(5)},
+		}
+
+		for testName, tc := range testCases {
+			Convey("Should convert "+testName+".", func() {
+				res, err := convertValue(tc.value, tc.t)
+
+				So(err, ShouldBeNil)
+				So(res.Interface(), ShouldResemble, tc.expected)
+			})
+		}
+
+		errorCases := map[string]struct {
+			value         interface{}
+			t             reflect.Type
+			expectedError string
+		}{
+			"invalid bool":       {value: "yes", t: reflect.TypeOf(false), expectedError: "cannot parse 'yes' as bool"},
+			"overflowing string": {value: "300", t: reflect.TypeOf(int8(0)), expectedError: "cannot parse '300' as int8"},
+			"fraction":           {value: 1.5, t: reflect.TypeOf(0), expectedError: "cannot convert 1.5 to int"},
+			"overflowing number": {value: 300, t: reflect.TypeOf(int8(0)), expectedError: "cannot convert 300 to int8"},
+			"negative number":    {value: -1, t: reflect.TypeOf(uint(0)), expectedError: "cannot convert -1 to uint"},
+			"invalid slice item": {value: "1,a", t: reflect.TypeOf([]int{}), expectedError: "cannot parse 'a' as int"},
+		}
+
+		for testName, tc := range errorCases {
+			Convey("Should return error for "+testName+".", func() {
+				_, err := convertValue(tc.value, tc.t)
+
+				So(err, ShouldBeError, tc.expectedError)
+			})
+		}
+	})
+}
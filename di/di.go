@@ -4,42 +4,108 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 )
 
 // Dependency is di dependency.
 type Dependency struct {
 	Name  string
 	Value interface{}
+	// Shared marks the dependency as singleton which ResolveNewDeep injects
+	// instead of creating new instance.
+	Shared bool
+	// Prototype makes the new instances of the dependency copies of Value
+	// instead of zero values. If Value has Clone method which takes no
+	// arguments and returns the type of Value, the copies are created with
+	// it. Otherwise Value is copied shallowly, together with the embedded
+	// struct pointers which contain marked fields.
+	Prototype bool
 }
 
 // NewContainer creates new di container.
 func NewContainer() *Container {
-	return &Container{dependencies: make(map[string]*dependencyMetadata)}
+	return &Container{
+		dependencies:    make(map[dependencyKey]*dependencyMetadata),
+		implementations: make(map[reflect.Type][]*dependencyMetadata),
+		dependents:      make(map[*dependencyMetadata]map[dependent]markedField),
+		modules:         make(map[string]*Module),
+	}
 }
 
 // Container is the di container.
 type Container struct {
-	dependencies map[string]*dependencyMetadata
+	dependencies map[dependencyKey]*dependencyMetadata
+	// implementations contains the registered dependencies which implement
+	// each interface looked up in the container.
+	implementations map[reflect.Type][]*dependencyMetadata
+	observers       []Observer
+	resolving       []*dependencyMetadata
+	// deepInstances contains the new instances of the registered
+	// dependencies created by ResolveNewDeep.
+	deepInstances map[*dependencyMetadata]*dependencyMetadata
+	config        ConfigSource
+	// dependents contains the fields of the registered dependencies which
+	// are injected with each registered dependency.
+	dependents  map[*dependencyMetadata]map[dependent]markedField
+	mockFactory MockFactory
+	// mocks contains the mocks of the interfaces created by mockFactory.
+	mocks map[reflect.Type]*mock
+	// modules contains the installed modules by name.
+	modules map[string]*Module
 }
 
 // Register adds the provided dependencies to the container.
 func (c *Container) Register(deps ...*Dependency) error {
 	for _, d := range deps {
-		dType := reflect.TypeOf(d.Value)
-		if !isValidValue(dType) {
-			return fmt.Errorf("%s should be pointer or interface", dType.String())
+		_, err := c.register(d, "")
+		if err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+// register adds the dependency installed by the provided module.
+func (c *Container) register(d *Dependency, module string) (*dependencyMetadata, error) {
+	meta, err := newDependencyMetadata(d)
+	if err != nil {
+		return nil, err
+	}
 
-		meta := generateDependencyMetadata(d)
-		key := getDependencyKey(meta.reflectType, d.Name)
-		if _, ok := c.dependencies[key]; ok {
-			return fmt.Errorf("duplicate dependency: %s", key)
+	if existing, ok := c.dependencies[meta.key]; ok {
+		if len(existing.module) > 0 {
+			return nil, fmt.Errorf("duplicate dependency: %s (registered by module %s)", meta.key, existing.module)
 		}
 
-		c.dependencies[key] = meta
+		return nil, fmt.Errorf("duplicate dependency: %s", meta.key)
 	}
 
-	return nil
+	meta.module = module
+	c.add(meta)
+	return meta, nil
+}
+
+// newDependencyMetadata validates the dependency and generates its metadata.
+func newDependencyMetadata(d *Dependency) (*dependencyMetadata, error) {
+	dType := reflect.TypeOf(d.Value)
+	if dType == nil {
+		return nil, errors.New("the dependency value must not be nil")
+	}
+
+	if !isInjectableType(dType) {
+		return nil, fmt.Errorf("%s should be pointer, struct values cannot be registered", dType.String())
+	}
+
+	meta := generateDependencyMetadata(d)
+	meta.key = getDependencyKey(meta.reflectType, d.Name)
+	return meta, nil
+}
+
+func (c *Container) add(meta *dependencyMetadata) {
+	c.dependencies[meta.key] = meta
+	c.indexImplementations(meta)
+	c.notify(func(o Observer) { o.OnRegister(meta.registration()) })
 }
 
 // ResolveAll populates the marked dependencies with the registered
@@ -63,19 +129,27 @@ func (c *Container) Resolve(out interface{}) error {
 // ResolveByName sets the out parameter to the resolved by name dependency value.
 func (c *Container) ResolveByName(name string, out interface{}) error {
 	return c.resolveWithFinder(func(isInterface bool) *dependencyMetadata {
-		return c.findDependency(out, name)
+		dep := c.findDependency(out, name)
+		if dep != nil {
+			dep.requested = true
+		}
+
+		return dep
 	}, out)
 }
 
 // ResolveNew returns new instance of the provided type.
 // The dependencies of the instance marked for resolving will not be new.
+// The dependencies registered with plain values are copied as they are.
+// Use ResolveNewDeep to create new instances of the dependencies as well.
 func (c *Container) ResolveNew(out interface{}) error {
 	return c.resolveWithFinder(func(isInterface bool) *dependencyMetadata {
 		dep := c.findDependency(out, "")
 		var resTypeElem reflect.Type
 		if dep == nil {
-			if isInterface {
-				// No dependency which implements the interface was registered.
+			if isInterface || reflect.TypeOf(out).Elem().Kind() != reflect.Struct {
+				// No dependency which implements the interface or has the
+				// type of the plain value was registered.
 				return nil
 			}
 
@@ -83,13 +157,152 @@ func (c *Container) ResolveNew(out interface{}) error {
 			resTypeElem = reflect.TypeOf(out).Elem()
 		} else {
 			// The out is struct which is registered in the container.
+			dep.requested = true
 			resTypeElem = dep.typeElem
 		}
 
-		return generateDependencyMetadata(&Dependency{Value: reflect.New(resTypeElem).Interface()})
+		res := c.newInstance(resTypeElem, dep)
+		if dep != nil && c.deepInstances != nil {
+			res.reused = true
+			c.deepInstances[dep] = res
+		}
+
+		return res
 	}, out)
 }
 
+// ResolveNewDeep returns new instance of the provided type like ResolveNew.
+// The dependencies of the instance marked for resolving are new instances
+// as well, recursively. Each registered dependency is created once per call,
+// so the new dependencies reference each other like the registered ones.
+// The dependencies registered as Shared are not created again.
+func (c *Container) ResolveNewDeep(out interface{}) error {
+	c.deepInstances = make(map[*dependencyMetadata]*dependencyMetadata)
+	defer func() { c.deepInstances = nil }()
+
+	return c.ResolveNew(out)
+}
+
+// Inject populates the marked fields of the provided struct pointer with
+// the registered dependencies. The target does not need to be registered
+// and it is not registered by Inject.
+func (c *Container) Inject(target interface{}) error {
+	t := reflect.TypeOf(target)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return errors.New("the target must be a pointer to struct")
+	}
+
+	if reflect.ValueOf(target).IsNil() {
+		return errors.New("the target must not be nil")
+	}
+
+	return c.resolveCore(generateDependencyMetadata(&Dependency{Value: target}))
+}
+
+// deepInstance returns the new instance of the registered dependency
+// created for ResolveNewDeep.
+func (c *Container) deepInstance(d *dependencyMetadata) *dependencyMetadata {
+	if d.Shared || d.fresh {
+		return d
+	}
+
+	if res, ok := c.deepInstances[d]; ok {
+		return res
+	}
+
+	res := c.newInstance(d.typeElem, d)
+	res.reused = true
+	c.deepInstances[d] = res
+	return res
+}
+
+// newInstance creates metadata of new instance of the provided type.
+// If the registered dependency is prototype, the instance is its copy.
+func (c *Container) newInstance(typeElem reflect.Type, registered *dependencyMetadata) *dependencyMetadata {
+	if registered != nil && registered.isPlainValue() {
+		// The plain values are copied on injection.
+		return registered
+	}
+
+	var value interface{}
+	if registered != nil && registered.Prototype {
+		value = cloneValue(registered)
+	} else {
+		value = reflect.New(typeElem).Interface()
+	}
+
+	res := generateDependencyMetadata(&Dependency{Value: value})
+	res.fresh = true
+	c.notify(func(o Observer) { o.OnConstruct(res.registration()) })
+	return res
+}
+
+// newFieldInstance creates new instance for field marked with the new
+// option. The instance has the type of the registered dependency which can
+// be injected in the field. If there is no such dependency and the field is
+// pointer, the instance has the type of the field element.
+func (c *Container) newFieldInstance(f markedField) (*dependencyMetadata, error) {
+	dep := c.findFieldDependency(f)
+	var typeElem reflect.Type
+	if dep != nil {
+		typeElem = dep.typeElem
+	} else if f.field.Type.Kind() == reflect.Ptr {
+		typeElem = f.field.Type.Elem()
+	} else {
+		return nil, nil
+	}
+
+	err := c.checkCircularNew(typeElem, f)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.newInstance(typeElem, dep), nil
+}
+
+// defaultFieldDependency returns the fallback dependency of field marked
+// with the default option when the dependency of the field is missing. It
+// is the dependency registered with the default name. If there is no such
+// dependency and the field is pointer, it is new zero value instance.
+func (c *Container) defaultFieldDependency(f markedField) (*dependencyMetadata, error) {
+	if len(f.tags.Default) > 0 {
+		if dep := c.findDependencyCore(f.field.Type, f.tags.Default); dep != nil {
+			return dep, nil
+		}
+	}
+
+	if f.field.Type.Kind() != reflect.Ptr {
+		return nil, nil
+	}
+
+	typeElem := f.field.Type.Elem()
+	err := c.checkCircularNew(typeElem, f)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.newInstance(typeElem, nil), nil
+}
+
+// checkCircularNew checks if new instance of the type is already resolving
+// through a chain of new instances. Creating another one would never end.
+// The chains through registered dependencies and the instances reused by
+// ResolveNewDeep end, because these dependencies are resolved once.
+func (c *Container) checkCircularNew(typeElem reflect.Type, f markedField) error {
+	for i := len(c.resolving) - 1; i >= 0; i-- {
+		r := c.resolving[i]
+		if !r.fresh || r.reused {
+			return nil
+		}
+
+		if r.typeElem == typeElem {
+			return fmt.Errorf("circular new dependency: %s", f.name)
+		}
+	}
+
+	return nil
+}
+
 func (c *Container) resolveWithFinder(finder func(isInterface bool) *dependencyMetadata, out interface{}) error {
 	resType := reflect.TypeOf(out)
 	if !isValidValue(resType) {
@@ -99,7 +312,7 @@ func (c *Container) resolveWithFinder(finder func(isInterface bool) *dependencyM
 	isInterface := isPointerTypePointerToInterface(resType)
 	dep := finder(isInterface)
 	if dep == nil {
-		return fmt.Errorf("unable to find registered dependency: %s", resType.String())
+		return fmt.Errorf("unable to find registered dependency: %s", getTypeName(resType))
 	}
 
 	err := c.resolveCore(dep)
@@ -108,9 +321,9 @@ func (c *Container) resolveWithFinder(finder func(isInterface bool) *dependencyM
 	}
 
 	var resValue reflect.Value
-	if isInterface {
-		// We need to use the actual pointer reflect value to set it
-		// to the provided interface.
+	if isInterface || dep.isPlainValue() {
+		// We need to use the actual reflect value to set it to the
+		// provided interface or plain value.
 		resValue = dep.reflectValue
 	} else {
 		resValue = dep.valueElem
@@ -135,6 +348,12 @@ func (c *Container) findDependency(out interface{}, name string) *dependencyMeta
 		dep = c.findDependencyCore(outType.Elem(), name)
 	} else {
 		dep = c.findDependencyCore(outType, name)
+		if dep == nil {
+			// The out parameter can point to plain value, e.g. *string.
+			if plain := c.findDependencyCore(outType.Elem(), name); plain != nil && plain.isPlainValue() {
+				dep = plain
+			}
+		}
 	}
 
 	return dep
@@ -145,56 +364,148 @@ func (c *Container) resolveCore(d *dependencyMetadata) error {
 		return nil
 	}
 
+	c.notify(func(o Observer) { o.OnResolveStart(d.registration()) })
+	var parent *dependencyMetadata
+	if len(c.resolving) > 0 {
+		parent = c.resolving[len(c.resolving)-1]
+	}
+
+	c.resolving = append(c.resolving, d)
+	start := now()
+	err := c.resolveFields(d)
+	duration := now().Sub(start)
+	c.resolving = c.resolving[:len(c.resolving)-1]
+	if err == nil {
+		d.timing = &resolveTiming{start: start, duration: duration, parent: parent}
+	}
+
+	c.notify(func(o Observer) { o.OnResolveEnd(d.registration(), duration, err) })
+
+	return err
+}
+
+func (c *Container) resolveFields(d *dependencyMetadata) error {
 	// Mark as complete to avoid circular dependency recursion.
 	// This requires each return with error to set the complete property to false.
 	d.complete = true
-	for i := 0; i < d.typeElem.NumField(); i++ {
-		field := d.typeElem.Field(i)
-		tags, err := getTags(field)
-		if err != nil {
+	fields, err := getMarkedFields(d.typeElem)
+	if err != nil {
+		d.complete = false
+		return fmt.Errorf("[%s] %s", getTypeName(d.reflectType), err.Error())
+	}
+
+	for _, f := range fields {
+		field := f.field
+		if !f.settable {
 			d.complete = false
-			return fmt.Errorf("[%s] %s", d.reflectType.String(), err.Error())
+			return fmt.Errorf("[%s] cannot set field %s", getTypeName(d.reflectType), f.name)
 		}
 
-		if tags == nil {
+		if !f.injectsDependency() {
+			err = c.injectValue(d, f)
+			if err != nil {
+				d.complete = false
+				return fmt.Errorf("[%s] %s", getTypeName(d.reflectType), err.Error())
+			}
+
 			continue
 		}
 
-		if !isValidValue(field.Type) || !isFieldExported(field) {
-			d.complete = false
-			return fmt.Errorf("[%s] cannot set field %s", d.reflectType.String(), field.Name)
+		var fieldDep *dependencyMetadata
+		if f.tags.New {
+			fieldDep, err = c.newFieldInstance(f)
+			if err != nil {
+				d.complete = false
+				return fmt.Errorf("[%s] %s", getTypeName(d.reflectType), err.Error())
+			}
+		} else {
+			fieldDep = c.findFieldDependency(f)
+			if fieldDep == nil && f.tags.HasDefault {
+				fieldDep, err = c.defaultFieldDependency(f)
+				if err != nil {
+					d.complete = false
+					return fmt.Errorf("[%s] %s", getTypeName(d.reflectType), err.Error())
+				}
+			}
+
+			if fieldDep != nil && d.key.reflectType == nil {
+				// The instances which are not registered, e.g. the targets of
+				// Inject, are populated by explicit calls only.
+				fieldDep.requested = true
+			}
+
+			if fieldDep != nil && c.deepInstances != nil {
+				fieldDep = c.deepInstance(fieldDep)
+			}
+
+			if fieldDep == nil && !f.tags.Optional {
+				fieldDep, err = c.mockDependency(f.field.Type)
+				if err != nil {
+					d.complete = false
+					return fmt.Errorf("[%s] %s", getTypeName(d.reflectType), err.Error())
+				}
+			}
+		}
+
+		if fieldDep == nil && (f.tags.Optional || (f.tags.HasDefault && f.field.Type.Kind() != reflect.Interface)) {
+			// The plain values without fallback keep their zero value.
+			continue
 		}
 
-		fieldDep := c.findDependencyCore(field.Type, tags.name)
 		if fieldDep == nil {
 			d.complete = false
-			return fmt.Errorf("[%s] unable to find registered dependency: %s", d.reflectType.String(), field.Name)
+			return fmt.Errorf("[%s] unable to find registered dependency: %s", getTypeName(d.reflectType), f.name)
 		}
 
-		err = c.resolveCore(fieldDep)
+		err = c.resolveFieldDependency(fieldDep)
 		if err != nil {
 			d.complete = false
-			return fmt.Errorf("[%s] %s", d.reflectType.String(), err.Error())
+			return fmt.Errorf("[%s] %s", getTypeName(d.reflectType), err.Error())
 		}
 
-		d.valueElem.Field(i).Set(fieldDep.reflectValue)
+		fieldByIndex(d.valueElem, f.index).Set(fieldDep.reflectValue)
+		c.addDependent(fieldDep, d, f)
+		if len(c.observers) > 0 {
+			c.notify(func(o Observer) { o.OnInject(d.registration(), field, fieldDep.registration()) })
+		}
 	}
 
 	return nil
 }
 
+// resolveFieldDependency resolves the dependency injected in field. The
+// dependencies which ResolveNewDeep injects as they are, like the shared
+// ones, are resolved with the registered dependencies, because they are
+// reused after the call.
+func (c *Container) resolveFieldDependency(d *dependencyMetadata) error {
+	if c.deepInstances == nil || d.fresh {
+		return c.resolveCore(d)
+	}
+
+	deepInstances := c.deepInstances
+	c.deepInstances = nil
+	defer func() { c.deepInstances = deepInstances }()
+
+	return c.resolveCore(d)
+}
+
+func (c *Container) findFieldDependency(f markedField) *dependencyMetadata {
+	if f.key.reflectType != nil {
+		return c.dependencies[f.key]
+	}
+
+	return c.findDependencyCore(f.field.Type, f.tags.Name)
+}
+
 func (c *Container) findDependencyCore(t reflect.Type, name string) *dependencyMetadata {
 	if t.Kind() == reflect.Interface {
-		for _, v := range c.dependencies {
+		for _, v := range c.getImplementations(t) {
 			if len(name) > 0 && v.Name != name {
 				// Skip other checks if name is provided and it does not match.
 				continue
 			}
 
-			if v.implements[t.String()] || v.reflectType.Implements(t) {
-				v.implements[t.String()] = true
-				return v
-			}
+			return v
 		}
 	} else {
 		key := getDependencyKey(t, name)
@@ -203,3 +514,40 @@ func (c *Container) findDependencyCore(t reflect.Type, name string) *dependencyM
 
 	return nil
 }
+
+// getImplementations returns the registered dependencies which implement
+// the provided interface. The result is indexed on the first lookup and
+// updated on each registration.
+func (c *Container) getImplementations(t reflect.Type) []*dependencyMetadata {
+	if res, ok := c.implementations[t]; ok {
+		return res
+	}
+
+	res := []*dependencyMetadata{}
+	for _, d := range c.dependencies {
+		if d.reflectType.Implements(t) {
+			res = append(res, d)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].key.String() < res[j].key.String() })
+	c.implementations[t] = res
+	return res
+}
+
+// indexImplementations adds the provided dependency to the indexed
+// interfaces it implements, keeping them sorted by key.
+func (c *Container) indexImplementations(d *dependencyMetadata) {
+	key := d.key.String()
+	for t, deps := range c.implementations {
+		if !d.reflectType.Implements(t) {
+			continue
+		}
+
+		i := sort.Search(len(deps), func(i int) bool { return deps[i].key.String() > key })
+		deps = append(deps, nil)
+		copy(deps[i+1:], deps[i:])
+		deps[i] = d
+		c.implementations[t] = deps
+	}
+}
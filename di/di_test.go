@@ -2,10 +2,13 @@ package di
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+const pkgPath = "github.com/TsvetanMilanov/go-simple-di/di"
+
 type rootDependency struct {
 	First     *firstLevelDependency `di:""`
 	Pointer   *pointerDependency    `di:""`
@@ -54,6 +57,27 @@ type third struct {
 
 func (b *builder) Work() string { return b.work }
 
+type circularDefault struct {
+	Next *circularDefault `di:"default=''"`
+}
+
+type newCycleFirst struct {
+	Second *newCycleSecond `di:""`
+}
+
+type newCycleSecond struct {
+	First *newCycleFirst `di:"new"`
+}
+
+type cloneable struct {
+	values []int
+	P      *pointerDependency `di:""`
+}
+
+func (c *cloneable) Clone() *cloneable {
+	return &cloneable{values: append([]int{}, c.values...)}
+}
+
 func TestDependencyInjection(t *testing.T) {
 	Convey("Container", t, func() {
 		Convey("Resolve", func() {
@@ -199,7 +223,7 @@ func TestDependencyInjection(t *testing.T) {
 
 					res := new(named)
 					err = c.Resolve(res)
-					So(err, ShouldBeError, "[*di.named] unable to find registered dependency: Struct")
+					So(err, ShouldBeError, "[*"+pkgPath+".named] unable to find registered dependency: Struct")
 				})
 				Convey("unnamed dependencies for named interfaces.", func() {
 					c := NewContainer()
@@ -214,7 +238,7 @@ func TestDependencyInjection(t *testing.T) {
 
 					res := new(named)
 					err = c.Resolve(res)
-					So(err, ShouldBeError, "[*di.named] unable to find registered dependency: Interface")
+					So(err, ShouldBeError, "[*"+pkgPath+".named] unable to find registered dependency: Interface")
 				})
 				Convey("when not all dependencies are registered.", func() {
 					c := NewContainer()
@@ -229,7 +253,7 @@ func TestDependencyInjection(t *testing.T) {
 					res := new(rootDependency)
 					err = c.Resolve(res)
 
-					So(err, ShouldBeError, "[*di.rootDependency] [*di.firstLevelDependency] [*di.secondLevelDependency] unable to find registered dependency: InterfaceThirdLevel")
+					So(err, ShouldBeError, "[*"+pkgPath+".rootDependency] [*"+pkgPath+".firstLevelDependency] [*"+pkgPath+".secondLevelDependency] unable to find registered dependency: InterfaceThirdLevel")
 				})
 				Convey("unexported properties.", func() {
 					type unexp struct {
@@ -246,7 +270,7 @@ func TestDependencyInjection(t *testing.T) {
 					r := new(unexp)
 					err = c.Resolve(r)
 
-					So(err, ShouldBeError, "[*di.unexp] cannot set field iAmNotExported")
+					So(err, ShouldBeError, "[*"+pkgPath+".unexp] cannot set field iAmNotExported")
 					So(r.iAmNotExported, ShouldBeNil)
 				})
 				Convey("when the tag is invalid.", func() {
@@ -263,7 +287,116 @@ func TestDependencyInjection(t *testing.T) {
 					r := new(invalidTag)
 					err = c.Resolve(r)
 
-					So(err, ShouldBeError, "[*di.invalidTag] "+getInvalidTagErr("name=").Error())
+					So(err, ShouldBeError, "[*"+pkgPath+".invalidTag] invalid di tag 'name=' at position 6: expected value")
+				})
+			})
+			Convey("Should skip optional fields which cannot be resolved.", func() {
+				c := NewContainer()
+				type optional struct {
+					Missing   *named             `di:"optional"`
+					Interface worker             `di:"optional,name=missing"`
+					Present   *pointerDependency `di:"optional"`
+				}
+
+				err := c.Register(&Dependency{Value: new(pointerDependency)})
+				So(err, ShouldBeNil)
+
+				res := new(optional)
+				err = c.ResolveNew(res)
+
+				So(err, ShouldBeNil)
+				So(res.Missing, ShouldBeNil)
+				So(res.Interface, ShouldBeNil)
+				So(res.Present, ShouldNotBeNil)
+			})
+			Convey("Should inject new instances in fields marked with new", func() {
+				type buffer struct {
+					data []string
+					P    *pointerDependency `di:""`
+				}
+				type owner struct {
+					Shared *buffer `di:""`
+					Own    *buffer `di:"new"`
+					W      worker  `di:"new"`
+				}
+
+				Convey("for registered dependencies.", func() {
+					c := NewContainer()
+					shared := &buffer{data: []string{"shared"}}
+					ptr := &pointerDependency{value: 5}
+					err := c.Register(
+						&Dependency{Value: shared},
+						&Dependency{Value: ptr},
+						&Dependency{Value: &builder{work: "registered"}},
+					)
+					So(err, ShouldBeNil)
+
+					first := new(owner)
+					second := new(owner)
+					err = c.ResolveNew(first)
+					So(err, ShouldBeNil)
+					err = c.ResolveNew(second)
+					So(err, ShouldBeNil)
+
+					So(first.Shared, ShouldEqual, shared)
+					So(second.Shared, ShouldEqual, shared)
+					So(first.Own, ShouldNotEqual, shared)
+					So(first.Own, ShouldNotEqual, second.Own)
+					So(first.Own.data, ShouldBeNil)
+					So(first.Own.P, ShouldEqual, ptr)
+					So(first.W, ShouldNotBeNil)
+					So(first.W.Work(), ShouldBeEmpty)
+					So(first.W, ShouldNotEqual, second.W)
+				})
+				Convey("for not registered pointers.", func() {
+					c := NewContainer()
+					type notRegistered struct {
+						Own *pointerDependency `di:"new"`
+					}
+
+					res := new(notRegistered)
+					err := c.ResolveNew(res)
+
+					So(err, ShouldBeNil)
+					So(res.Own, ShouldNotBeNil)
+				})
+				Convey("and fail for not registered interfaces.", func() {
+					c := NewContainer()
+					type notRegistered struct {
+						W worker `di:"new"`
+					}
+
+					err := c.ResolveNew(new(notRegistered))
+
+					So(err, ShouldBeError, "[*"+pkgPath+".notRegistered] unable to find registered dependency: W")
+				})
+				Convey("and fail for circular new dependencies.", func() {
+					c := NewContainer()
+					type circular struct {
+						Self *circular `di:"new"`
+					}
+
+					err := c.ResolveNew(new(circular))
+
+					So(err, ShouldBeError, "[*"+pkgPath+".circular] circular new dependency: Self")
+				})
+				Convey("through registered dependencies regardless of the resolve order.", func() {
+					second := new(newCycleSecond)
+					c := NewContainer()
+					err := c.Register(&Dependency{Value: new(newCycleFirst)}, &Dependency{Value: second})
+					So(err, ShouldBeNil)
+
+					res := new(newCycleFirst)
+					err = c.ResolveNew(res)
+
+					So(err, ShouldBeNil)
+					So(res.Second, ShouldEqual, second)
+					So(second.First.Second, ShouldEqual, second)
+
+					So(c.ResolveAll(), ShouldBeNil)
+					res = new(newCycleFirst)
+					So(c.ResolveNew(res), ShouldBeNil)
+					So(res.Second, ShouldEqual, second)
 				})
 			})
 			Convey("Should NOT resolve fields without tags", func() {
@@ -339,7 +472,7 @@ func TestDependencyInjection(t *testing.T) {
 
 					res := new(builder)
 					err = c.ResolveByName("test", res)
-					So(err, ShouldBeError, "unable to find registered dependency: *di.builder")
+					So(err, ShouldBeError, "unable to find registered dependency: *"+pkgPath+".builder")
 				})
 				Convey("unnamed structs which implement interfaces.", func() {
 					c := NewContainer()
@@ -349,7 +482,7 @@ func TestDependencyInjection(t *testing.T) {
 
 					res := new(worker)
 					err = c.ResolveByName("test", res)
-					So(err, ShouldBeError, "unable to find registered dependency: *di.worker")
+					So(err, ShouldBeError, "unable to find registered dependency: *"+pkgPath+".worker")
 				})
 			})
 		})
@@ -397,7 +530,7 @@ func TestDependencyInjection(t *testing.T) {
 						So(err, ShouldBeNil)
 						So(nValue.Work(), ShouldNotEqual, initial.Work())
 						So(nValue.Work(), ShouldNotEqual, prev.Work())
-						(*n).(*builder).work = "Testing: " + string(i)
+						(*n).(*builder).work = "Testing: " + string(rune(i))
 						prev = (*n).(*builder)
 
 						old := new(worker)
@@ -429,30 +562,646 @@ func TestDependencyInjection(t *testing.T) {
 					So(res.PointerThirdLevel.value, ShouldEqual, v)
 				})
 			})
+			Convey("Should copy prototype dependencies", func() {
+				Convey("shallowly.", func() {
+					c := NewContainer()
+					prototype := &secondLevelDependency{}
+					ptr := &pointerDependency{value: 10}
+					err := c.Register(
+						&Dependency{Value: prototype, Prototype: true},
+						&Dependency{Value: ptr},
+						&Dependency{Value: &builder{work: "work"}, Prototype: true},
+					)
+					So(err, ShouldBeNil)
+
+					res := new(pointerDependency)
+					So(c.ResolveNew(res), ShouldBeNil)
+					So(res.value, ShouldEqual, 0)
+
+					w := new(worker)
+					So(c.ResolveNew(w), ShouldBeNil)
+					So((*w).Work(), ShouldEqual, "work")
+
+					second := new(secondLevelDependency)
+					So(c.ResolveNew(second), ShouldBeNil)
+					So(second.PointerThirdLevel, ShouldEqual, ptr)
+					So(second.InterfaceThirdLevel.Work(), ShouldEqual, "work")
+					So(prototype.PointerThirdLevel, ShouldBeNil)
+				})
+				Convey("with their Clone method.", func() {
+					c := NewContainer()
+					prototype := &cloneable{values: []int{1, 2}}
+					ptr := new(pointerDependency)
+					err := c.Register(
+						&Dependency{Value: prototype, Prototype: true},
+						&Dependency{Value: ptr},
+					)
+					So(err, ShouldBeNil)
+
+					res := new(cloneable)
+					So(c.ResolveNew(res), ShouldBeNil)
+
+					So(res.values, ShouldResemble, []int{1, 2})
+					So(res.P, ShouldEqual, ptr)
+					res.values[0] = 5
+					So(prototype.values, ShouldResemble, []int{1, 2})
+				})
+				Convey("in fields marked with new.", func() {
+					c := NewContainer()
+					type owner struct {
+						W worker `di:"new"`
+					}
+
+					err := c.Register(&Dependency{Value: &builder{work: "template"}, Prototype: true})
+					So(err, ShouldBeNil)
+
+					res := new(owner)
+					So(c.ResolveNew(res), ShouldBeNil)
+
+					So(res.W.Work(), ShouldEqual, "template")
+				})
+				Convey("with their embedded pointers.", func() {
+					type Base struct {
+						Ptr *pointerDependency `di:""`
+						New *pointerDependency `di:"new"`
+					}
+					type handler struct {
+						*Base
+					}
+
+					c := NewContainer()
+					prototype := &handler{Base: &Base{}}
+					ptr := new(pointerDependency)
+					err := c.Register(&Dependency{Value: prototype, Prototype: true}, &Dependency{Value: ptr})
+					So(err, ShouldBeNil)
+					So(c.ResolveAll(), ShouldBeNil)
+					registered := prototype.New
+
+					first := new(handler)
+					So(c.ResolveNew(first), ShouldBeNil)
+					second := new(handler)
+					So(c.ResolveNew(second), ShouldBeNil)
+					deep := new(handler)
+					So(c.ResolveNewDeep(deep), ShouldBeNil)
+
+					So(first.Base, ShouldNotPointTo, prototype.Base)
+					So(first.Ptr, ShouldPointTo, ptr)
+					So(first.New, ShouldNotPointTo, second.New)
+					So(deep.Ptr, ShouldNotPointTo, ptr)
+					So(prototype.Ptr, ShouldPointTo, ptr)
+					So(prototype.New, ShouldPointTo, registered)
+				})
+			})
 			Convey("Should fail to resolve not registered struct from provided interface.", func() {
 				c := NewContainer()
 				res := new(worker)
 
 				err := c.ResolveNew(res)
 
-				So(err, ShouldBeError, "unable to find registered dependency: *di.worker")
+				So(err, ShouldBeError, "unable to find registered dependency: *"+pkgPath+".worker")
 				So(*res, ShouldBeNil)
 			})
 		})
 
+		Convey("ResolveNewDeep", func() {
+			newContainer := func(sharedPtr bool) (*Container, *pointerDependency) {
+				c := NewContainer()
+				ptr := &pointerDependency{value: 5}
+				err := c.Register(
+					&Dependency{Value: new(rootDependency)},
+					&Dependency{Value: new(firstLevelDependency)},
+					&Dependency{Value: new(secondLevelDependency)},
+					&Dependency{Value: ptr, Shared: sharedPtr},
+					&Dependency{Value: &builder{work: "registered"}},
+				)
+				So(err, ShouldBeNil)
+				So(c.ResolveAll(), ShouldBeNil)
+
+				return c, ptr
+			}
+
+			Convey("Should create new instances of all dependencies.", func() {
+				c, ptr := newContainer(false)
+				registered := new(rootDependency)
+				So(c.Resolve(registered), ShouldBeNil)
+
+				res := new(rootDependency)
+				err := c.ResolveNewDeep(res)
+
+				So(err, ShouldBeNil)
+				So(res.First, ShouldNotEqual, registered.First)
+				So(res.First.Second, ShouldNotEqual, registered.First.Second)
+				So(res.Pointer, ShouldNotEqual, ptr)
+				So(res.Pointer.value, ShouldEqual, 0)
+				So(res.Interface, ShouldNotEqual, registered.Interface)
+				So(res.Interface.Work(), ShouldBeEmpty)
+			})
+			Convey("Should create each dependency once per call.", func() {
+				c, _ := newContainer(false)
+				first := new(rootDependency)
+				second := new(rootDependency)
+
+				So(c.ResolveNewDeep(first), ShouldBeNil)
+				So(c.ResolveNewDeep(second), ShouldBeNil)
+
+				So(first.Pointer, ShouldEqual, first.First.PointerSecondLevel)
+				So(first.Pointer, ShouldEqual, first.First.Second.PointerThirdLevel)
+				So(first.Interface, ShouldEqual, first.First.Second.InterfaceThirdLevel)
+				So(first.Pointer, ShouldNotEqual, second.Pointer)
+				So(first.First, ShouldNotEqual, second.First)
+			})
+			Convey("Should NOT create new instances of shared dependencies.", func() {
+				c, ptr := newContainer(true)
+				res := new(rootDependency)
+
+				So(c.ResolveNewDeep(res), ShouldBeNil)
+
+				So(res.Pointer, ShouldEqual, ptr)
+				So(res.First.Second.PointerThirdLevel, ShouldEqual, ptr)
+			})
+			Convey("Should resolve the shared dependencies with the registered dependencies.", func() {
+				c := NewContainer()
+				shared := new(secondLevelDependency)
+				ptr := new(pointerDependency)
+				err := c.Register(
+					&Dependency{Value: new(firstLevelDependency)},
+					&Dependency{Value: shared, Shared: true},
+					&Dependency{Value: ptr},
+					&Dependency{Value: &builder{work: "registered"}},
+				)
+				So(err, ShouldBeNil)
+
+				res := new(firstLevelDependency)
+				So(c.ResolveNewDeep(res), ShouldBeNil)
+
+				So(res.Second, ShouldEqual, shared)
+				So(res.PointerSecondLevel, ShouldNotEqual, ptr)
+				So(shared.PointerThirdLevel, ShouldEqual, ptr)
+				So(shared.InterfaceThirdLevel.Work(), ShouldEqual, "registered")
+			})
+			Convey("Should resolve circular dependencies to the new instances.", func() {
+				c := NewContainer()
+				err := c.Register(
+					&Dependency{Value: new(first)},
+					&Dependency{Value: new(second)},
+					&Dependency{Value: new(pointerDependency)},
+				)
+				So(err, ShouldBeNil)
+
+				res := new(first)
+				err = c.ResolveNewDeep(res)
+
+				So(err, ShouldBeNil)
+				So(res.S.F.S, ShouldEqual, res.S)
+			})
+			Convey("Should NOT affect the following ResolveNew calls.", func() {
+				c, ptr := newContainer(false)
+				So(c.ResolveNewDeep(new(rootDependency)), ShouldBeNil)
+
+				res := new(rootDependency)
+				So(c.ResolveNew(res), ShouldBeNil)
+
+				So(res.Pointer, ShouldEqual, ptr)
+			})
+		})
+
+		Convey("Inject", func() {
+			Convey("Should populate the marked fields of not registered struct.", func() {
+				c := NewContainer()
+				ptr := &pointerDependency{value: 5}
+				err := c.Register(
+					&Dependency{Value: ptr},
+					&Dependency{Value: &builder{work: "inject"}},
+				)
+				So(err, ShouldBeNil)
+
+				target := &secondLevelDependency{}
+				err = c.Inject(target)
+
+				So(err, ShouldBeNil)
+				So(target.PointerThirdLevel, ShouldEqual, ptr)
+				So(target.InterfaceThirdLevel.Work(), ShouldEqual, "inject")
+				So(c.Registrations(), ShouldHaveLength, 2)
+			})
+			Convey("Should keep the unmarked fields.", func() {
+				c := NewContainer()
+				err := c.Register(&Dependency{Value: new(pointerDependency)})
+				So(err, ShouldBeNil)
+
+				type handler struct {
+					name string
+					P    *pointerDependency `di:""`
+				}
+
+				target := &handler{name: "handler"}
+				err = c.Inject(target)
+
+				So(err, ShouldBeNil)
+				So(target.name, ShouldEqual, "handler")
+				So(target.P, ShouldNotBeNil)
+			})
+			Convey("Should return resolve errors.", func() {
+				c := NewContainer()
+				err := c.Inject(new(secondLevelDependency))
+
+				So(err, ShouldBeError, "[*"+pkgPath+".secondLevelDependency] unable to find registered dependency: PointerThirdLevel")
+			})
+			Convey("Should validate the target", func() {
+				c := NewContainer()
+
+				Convey("to be pointer to struct.", func() {
+					So(c.Inject(secondLevelDependency{}), ShouldBeError, "the target must be a pointer to struct")
+					So(c.Inject(new(int)), ShouldBeError, "the target must be a pointer to struct")
+					So(c.Inject(nil), ShouldBeError, "the target must be a pointer to struct")
+				})
+				Convey("to be non nil.", func() {
+					var target *secondLevelDependency
+					So(c.Inject(target), ShouldBeError, "the target must not be nil")
+				})
+			})
+		})
+
+		Convey("Embedded structs", func() {
+			type Base struct {
+				Ptr    *pointerDependency `di:""`
+				Worker worker             `di:"optional"`
+			}
+			type base struct {
+				Ptr *pointerDependency `di:""`
+			}
+			newContainer := func() (*Container, *pointerDependency) {
+				c := NewContainer()
+				ptr := &pointerDependency{value: 5}
+				err := c.Register(&Dependency{Value: ptr})
+				So(err, ShouldBeNil)
+				return c, ptr
+			}
+
+			Convey("Should inject the fields of embedded struct.", func() {
+				type handler struct {
+					base
+					Own *pointerDependency `di:""`
+				}
+
+				c, ptr := newContainer()
+				target := &handler{}
+				err := c.Inject(target)
+
+				So(err, ShouldBeNil)
+				So(target.Ptr, ShouldEqual, ptr)
+				So(target.Own, ShouldEqual, ptr)
+			})
+			Convey("Should allocate nil embedded pointers.", func() {
+				type handler struct {
+					*Base
+				}
+
+				c, ptr := newContainer()
+				target := &handler{}
+				err := c.Inject(target)
+
+				So(err, ShouldBeNil)
+				So(target.Base, ShouldNotBeNil)
+				So(target.Ptr, ShouldEqual, ptr)
+				So(target.Worker, ShouldBeNil)
+			})
+			Convey("Should keep the allocated embedded pointers.", func() {
+				type handler struct {
+					*Base
+				}
+
+				c, ptr := newContainer()
+				b := &Base{}
+				target := &handler{Base: b}
+				err := c.Inject(target)
+
+				So(err, ShouldBeNil)
+				So(target.Base, ShouldEqual, b)
+				So(b.Ptr, ShouldEqual, ptr)
+			})
+			Convey("Should inject the registered dependency in tagged embedded pointer.", func() {
+				type handler struct {
+					*Base `di:""`
+				}
+
+				c, ptr := newContainer()
+				b := &Base{}
+				err := c.Register(&Dependency{Value: b})
+				So(err, ShouldBeNil)
+
+				target := &handler{}
+				err = c.Inject(target)
+
+				So(err, ShouldBeNil)
+				So(target.Base, ShouldEqual, b)
+				So(b.Ptr, ShouldEqual, ptr)
+			})
+			Convey("Should return error for fields of unexported embedded pointer.", func() {
+				type handler struct {
+					*base
+				}
+
+				c, _ := newContainer()
+				err := c.Inject(&handler{})
+
+				So(err, ShouldBeError, "[*"+pkgPath+".handler] cannot set field base.Ptr")
+			})
+			Convey("Should return the path of missing promoted field.", func() {
+				type handler struct {
+					base
+				}
+
+				c := NewContainer()
+				err := c.Inject(&handler{})
+
+				So(err, ShouldBeError, "[*"+pkgPath+".handler] unable to find registered dependency: base.Ptr")
+			})
+		})
+
+		Convey("Plain values", func() {
+			type handlerFunc func() string
+			type server struct {
+				Port    int            `di:"name=http.port"`
+				Timeout time.Duration  `di:""`
+				Hosts   []string       `di:""`
+				Limits  map[string]int `di:"optional"`
+				Handler handlerFunc    `di:""`
+				Events  chan string    `di:""`
+			}
+			newContainer := func() *Container {
+				c := NewContainer()
+				err := c.Register(
+					&Dependency{Name: "http.port", Value: 8080},
+					&Dependency{Value: 5 * time.Second},
+					&Dependency{Value: []string{"a", "b"}},
+					&Dependency{Value: handlerFunc(func() string { return "handled" })},
+					&Dependency{Value: make(chan string, 1)},
+				)
+				So(err, ShouldBeNil)
+				return c
+			}
+
+			Convey("Should inject the registered plain values.", func() {
+				c := newContainer()
+				res := new(server)
+				err := c.Inject(res)
+
+				So(err, ShouldBeNil)
+				So(res.Port, ShouldEqual, 8080)
+				So(res.Timeout, ShouldEqual, 5*time.Second)
+				So(res.Hosts, ShouldResemble, []string{"a", "b"})
+				So(res.Limits, ShouldBeNil)
+				So(res.Handler(), ShouldEqual, "handled")
+				So(res.Events, ShouldHaveLength, 0)
+				So(cap(res.Events), ShouldEqual, 1)
+			})
+			Convey("Should resolve plain values.", func() {
+				c := newContainer()
+				var port int
+				err := c.ResolveByName("http.port", &port)
+				So(err, ShouldBeNil)
+				So(port, ShouldEqual, 8080)
+
+				var hosts []string
+				err = c.Resolve(&hosts)
+				So(err, ShouldBeNil)
+				So(hosts, ShouldResemble, []string{"a", "b"})
+
+				var timeout time.Duration
+				err = c.ResolveNew(&timeout)
+				So(err, ShouldBeNil)
+				So(timeout, ShouldEqual, 5*time.Second)
+			})
+			Convey("Should return error for missing plain value.", func() {
+				c := NewContainer()
+				var port int
+				err := c.Resolve(&port)
+
+				So(err, ShouldBeError, "unable to find registered dependency: *int")
+			})
+			Convey("Should return error for pointer to pointer out parameter.", func() {
+				c := NewContainer()
+				err := c.Register(&Dependency{Value: new(pointerDependency)})
+				So(err, ShouldBeNil)
+
+				var ptr *pointerDependency
+				err = c.Resolve(&ptr)
+				So(err, ShouldBeError, "unable to find registered dependency: **"+pkgPath+".pointerDependency")
+
+				err = c.ResolveNew(&ptr)
+				So(err, ShouldBeError, "unable to find registered dependency: **"+pkgPath+".pointerDependency")
+				So(ptr, ShouldBeNil)
+			})
+			Convey("Should inject new instance in field with plain value.", func() {
+				type withNew struct {
+					Hosts []string `di:"new"`
+				}
+
+				c := newContainer()
+				res := new(withNew)
+				err := c.Inject(res)
+
+				So(err, ShouldBeNil)
+				So(res.Hosts, ShouldResemble, []string{"a", "b"})
+			})
+			Convey("Should return error for tagged struct value fields.", func() {
+				type withStruct struct {
+					Value pointerDependency `di:""`
+				}
+
+				c := newContainer()
+				err := c.Inject(new(withStruct))
+
+				So(err, ShouldBeError, "[*"+pkgPath+".withStruct] cannot set field Value")
+			})
+		})
+
+		Convey("Default", func() {
+			type withDefault struct {
+				Ptr    *pointerDependency `di:"name=custom,default=fallback"`
+				Worker worker             `di:"name=custom,default=noop"`
+			}
+
+			Convey("Should inject the named dependency when it is registered.", func() {
+				c := NewContainer()
+				custom := &pointerDependency{value: 1}
+				err := c.Register(
+					&Dependency{Name: "custom", Value: custom},
+					&Dependency{Name: "fallback", Value: new(pointerDependency)},
+					&Dependency{Name: "custom", Value: &builder{work: "custom"}},
+					&Dependency{Name: "noop", Value: &builder{work: "noop"}},
+				)
+				So(err, ShouldBeNil)
+
+				res := new(withDefault)
+				err = c.Inject(res)
+
+				So(err, ShouldBeNil)
+				So(res.Ptr, ShouldEqual, custom)
+				So(res.Worker.Work(), ShouldEqual, "custom")
+			})
+			Convey("Should inject the fallback registration.", func() {
+				c := NewContainer()
+				fallback := &pointerDependency{value: 2}
+				err := c.Register(
+					&Dependency{Name: "fallback", Value: fallback},
+					&Dependency{Name: "noop", Value: &builder{work: "noop"}},
+				)
+				So(err, ShouldBeNil)
+
+				res := new(withDefault)
+				err = c.Inject(res)
+
+				So(err, ShouldBeNil)
+				So(res.Ptr, ShouldEqual, fallback)
+				So(res.Worker.Work(), ShouldEqual, "noop")
+			})
+			Convey("Should inject zero value instance in pointer without fallback.", func() {
+				type zeroDefault struct {
+					First  *secondLevelDependency `di:"default=''"`
+					Second *secondLevelDependency `di:"default=missing"`
+				}
+
+				c := NewContainer()
+				ptr := new(pointerDependency)
+				err := c.Register(
+					&Dependency{Value: ptr},
+					&Dependency{Value: &builder{work: "zero"}},
+				)
+				So(err, ShouldBeNil)
+
+				res := new(zeroDefault)
+				err = c.Inject(res)
+
+				So(err, ShouldBeNil)
+				So(res.First, ShouldNotBeNil)
+				So(res.First, ShouldNotEqual, res.Second)
+				So(res.First.PointerThirdLevel, ShouldEqual, ptr)
+				So(res.Second.InterfaceThirdLevel.Work(), ShouldEqual, "zero")
+			})
+			Convey("Should keep the zero value of plain values without fallback.", func() {
+				type plainDefault struct {
+					Port int `di:"name=port,default=defaultPort"`
+				}
+
+				c := NewContainer()
+				res := new(plainDefault)
+				err := c.Inject(res)
+
+				So(err, ShouldBeNil)
+				So(res.Port, ShouldEqual, 0)
+
+				err = c.Register(&Dependency{Name: "defaultPort", Value: 80})
+				So(err, ShouldBeNil)
+
+				err = c.Inject(res)
+
+				So(err, ShouldBeNil)
+				So(res.Port, ShouldEqual, 80)
+			})
+			Convey("Should return error for interface without fallback.", func() {
+				c := NewContainer()
+				err := c.Register(&Dependency{Name: "fallback", Value: new(pointerDependency)})
+				So(err, ShouldBeNil)
+
+				err = c.Inject(new(withDefault))
+
+				So(err, ShouldBeError, "[*"+pkgPath+".withDefault] unable to find registered dependency: Worker")
+			})
+			Convey("Should return error for circular zero value instances.", func() {
+				c := NewContainer()
+				err := c.Inject(new(circularDefault))
+
+				So(err, ShouldBeError, "[*"+pkgPath+".circularDefault] [*"+pkgPath+".circularDefault] circular new dependency: Next")
+			})
+			Convey("Should validate the options used with default.", func() {
+				type withOptional struct {
+					Ptr *pointerDependency `di:"optional,default=fallback"`
+				}
+
+				c := NewContainer()
+				err := c.Inject(new(withOptional))
+
+				So(err, ShouldBeError, "[*"+pkgPath+".withOptional] di tag option 'default' cannot be used with 'new' or 'optional' for field Ptr")
+			})
+		})
+
 		Convey("Register", func() {
-			Convey("Should validate the dependency value to be pointer.", func() {
+			Convey("Should validate the dependency value not to be struct.", func() {
 				c := NewContainer()
 				err := c.Register(&Dependency{Value: pointerDependency{}})
 
-				So(err, ShouldBeError, "di.pointerDependency should be pointer or interface")
+				So(err, ShouldBeError, "di.pointerDependency should be pointer, struct values cannot be registered")
+			})
+			Convey("Should validate the dependency value not to be nil.", func() {
+				c := NewContainer()
+				err := c.Register(&Dependency{})
+
+				So(err, ShouldBeError, "the dependency value must not be nil")
 			})
 			Convey("Should check for duplicate dependency registration,", func() {
 				c := NewContainer()
 				d := &Dependency{Value: new(pointerDependency)}
 				err := c.Register(d, d)
 
-				So(err, ShouldBeError, "duplicate dependency: -*di.pointerDependency-ptr")
+				So(err, ShouldBeError, "duplicate dependency: *"+pkgPath+".pointerDependency")
+			})
+			Convey("Should distinguish types with the same name from different scopes.", func() {
+				newFirst := func() interface{} {
+					type settings struct{ first int }
+					return new(settings)
+				}
+				newSecond := func() interface{} {
+					type settings struct{ second int }
+					return new(settings)
+				}
+
+				c := NewContainer()
+				err := c.Register(
+					&Dependency{Value: newFirst()},
+					&Dependency{Value: newSecond()},
+				)
+
+				So(err, ShouldBeNil)
+				So(c.Registrations(), ShouldHaveLength, 2)
+			})
+			Convey("Should include the name of named duplicate dependency.", func() {
+				c := NewContainer()
+				d := &Dependency{Value: new(pointerDependency), Name: "named"}
+				err := c.Register(d, d)
+
+				So(err, ShouldBeError, "duplicate dependency: *"+pkgPath+".pointerDependency (name: named)")
+			})
+			Convey("Should add the dependency to the already looked up interfaces.", func() {
+				c := NewContainer()
+				err := c.Register(&Dependency{Value: &builder{work: "first"}, Name: "first"})
+				So(err, ShouldBeNil)
+
+				res := new(worker)
+				err = c.ResolveByName("second", res)
+				So(err, ShouldBeError, "unable to find registered dependency: *"+pkgPath+".worker")
+
+				err = c.Register(&Dependency{Value: &builder{work: "second"}, Name: "second"})
+				So(err, ShouldBeNil)
+
+				err = c.ResolveByName("second", res)
+				So(err, ShouldBeNil)
+				So((*res).Work(), ShouldEqual, "second")
+			})
+			Convey("Should keep the looked up interfaces sorted by key.", func() {
+				c := NewContainer()
+				err := c.Register(&Dependency{Value: &builder{work: "y"}, Name: "y"})
+				So(err, ShouldBeNil)
+				res := new(worker)
+				So(c.Resolve(res), ShouldBeNil)
+				So((*res).Work(), ShouldEqual, "y")
+
+				err = c.Register(&Dependency{Value: &builder{work: "x"}, Name: "x"})
+				So(err, ShouldBeNil)
+
+				err = c.Resolve(res)
+				So(err, ShouldBeNil)
+				So((*res).Work(), ShouldEqual, "x")
 			})
 		})
 
@@ -490,7 +1239,7 @@ func TestDependencyInjection(t *testing.T) {
 
 				err := c.ResolveAll()
 
-				So(err, ShouldBeError, "[*di.firstLevelDependency] unable to find registered dependency: Second")
+				So(err, ShouldBeError, "[*"+pkgPath+".firstLevelDependency] unable to find registered dependency: Second")
 			})
 		})
 	})
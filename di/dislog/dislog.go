@@ -0,0 +1,112 @@
+//go:build go1.21
+// +build go1.21
+
+// Package dislog provides di.Observer which logs the activities of the
+// di container with log/slog.
+package dislog
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/TsvetanMilanov/go-simple-di/di"
+)
+
+// Levels contains the levels used for the different container activities.
+type Levels struct {
+	Register     slog.Level
+	ResolveStart slog.Level
+	Resolve      slog.Level
+	Inject       slog.Level
+	Construct    slog.Level
+	Error        slog.Level
+}
+
+// DefaultLevels logs the resolve errors with error level, the completed
+// resolves with info level and all other activities with debug level.
+var DefaultLevels = Levels{
+	Register:     slog.LevelDebug,
+	ResolveStart: slog.LevelDebug,
+	Resolve:      slog.LevelInfo,
+	Inject:       slog.LevelDebug,
+	Construct:    slog.LevelDebug,
+	Error:        slog.LevelError,
+}
+
+// Observer logs the container activities.
+type Observer struct {
+	Logger *slog.Logger
+	Levels Levels
+}
+
+// New creates new Observer which logs with the provided logger and DefaultLevels.
+// If logger is nil, slog.Default() is used.
+func New(logger *slog.Logger) *Observer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Observer{Logger: logger, Levels: DefaultLevels}
+}
+
+// OnRegister implements di.Observer.
+func (o *Observer) OnRegister(r di.Registration) {
+	o.log(o.Levels.Register, "di: registered dependency", registrationAttrs(r)...)
+}
+
+// OnResolveStart implements di.Observer.
+func (o *Observer) OnResolveStart(r di.Registration) {
+	o.log(o.Levels.ResolveStart, "di: resolving dependency", registrationAttrs(r)...)
+}
+
+// OnResolveEnd implements di.Observer.
+func (o *Observer) OnResolveEnd(r di.Registration, duration time.Duration, err error) {
+	attrs := append(registrationAttrs(r), slog.Duration("duration", duration))
+	if err != nil {
+		o.log(o.Levels.Error, "di: failed to resolve dependency", append(attrs, slog.Any("error", err))...)
+		return
+	}
+
+	o.log(o.Levels.Resolve, "di: resolved dependency", attrs...)
+}
+
+// OnInject implements di.Observer.
+func (o *Observer) OnInject(parent di.Registration, field reflect.StructField, dep di.Registration) {
+	o.log(o.Levels.Inject, "di: injected dependency",
+		slog.String("type", typeName(parent.Type)),
+		slog.String("field", field.Name),
+		slog.String("dependency", typeName(dep.Type)),
+		slog.String("dependency_name", dep.Name),
+	)
+}
+
+// OnConstruct implements di.Observer.
+func (o *Observer) OnConstruct(r di.Registration) {
+	o.log(o.Levels.Construct, "di: constructed new instance", registrationAttrs(r)...)
+}
+
+func (o *Observer) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	ctx := context.Background()
+	if !o.Logger.Enabled(ctx, level) {
+		return
+	}
+
+	o.Logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+func registrationAttrs(r di.Registration) []slog.Attr {
+	return []slog.Attr{
+		slog.String("type", typeName(r.Type)),
+		slog.String("name", r.Name),
+	}
+}
+
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return "<nil>"
+	}
+
+	return t.String()
+}
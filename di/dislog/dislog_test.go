@@ -0,0 +1,96 @@
+//go:build go1.21
+// +build go1.21
+
+package dislog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/TsvetanMilanov/go-simple-di/di"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type dep struct {
+	value int
+}
+
+type root struct {
+	Dep *dep `di:""`
+}
+
+type broken struct {
+	Missing *root `di:""`
+}
+
+func newLogger(buf *bytes.Buffer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "duration" {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	}))
+}
+
+func TestObserver(t *testing.T) {
+	Convey("Observer", t, func() {
+		buf := new(bytes.Buffer)
+
+		Convey("Should log all activities with debug level.", func() {
+			c := di.NewContainer()
+			c.AddObserver(New(newLogger(buf, slog.LevelDebug)))
+			err := c.Register(
+				&di.Dependency{Value: new(root)},
+				&di.Dependency{Value: new(dep), Name: "named"},
+				&di.Dependency{Value: new(dep)},
+			)
+			So(err, ShouldBeNil)
+
+			err = c.Resolve(new(root))
+			So(err, ShouldBeNil)
+
+			So(strings.Split(strings.TrimSpace(buf.String()), "\n"), ShouldResemble, []string{
+				`level=DEBUG msg="di: registered dependency" type=*dislog.root name=""`,
+				`level=DEBUG msg="di: registered dependency" type=*dislog.dep name=named`,
+				`level=DEBUG msg="di: registered dependency" type=*dislog.dep name=""`,
+				`level=DEBUG msg="di: resolving dependency" type=*dislog.root name=""`,
+				`level=DEBUG msg="di: resolving dependency" type=*dislog.dep name=""`,
+				`level=INFO msg="di: resolved dependency" type=*dislog.dep name=""`,
+				`level=DEBUG msg="di: injected dependency" type=*dislog.root field=Dep dependency=*dislog.dep dependency_name=""`,
+				`level=INFO msg="di: resolved dependency" type=*dislog.root name=""`,
+			})
+		})
+		Convey("Should log errors.", func() {
+			c := di.NewContainer()
+			c.AddObserver(New(newLogger(buf, slog.LevelError)))
+			err := c.Register(&di.Dependency{Value: new(broken)})
+			So(err, ShouldBeNil)
+
+			err = c.ResolveAll()
+			So(err, ShouldNotBeNil)
+
+			So(strings.TrimSpace(buf.String()), ShouldEqual,
+				`level=ERROR msg="di: failed to resolve dependency" type=*dislog.broken name="" error="[*github.com/TsvetanMilanov/go-simple-di/di/dislog.broken] unable to find registered dependency: Missing"`)
+		})
+		Convey("Should use the configured levels.", func() {
+			c := di.NewContainer()
+			o := New(newLogger(buf, slog.LevelInfo))
+			o.Levels.Construct = slog.LevelWarn
+			c.AddObserver(o)
+
+			err := c.ResolveNew(new(dep))
+			So(err, ShouldBeNil)
+
+			So(strings.Split(strings.TrimSpace(buf.String()), "\n"), ShouldResemble, []string{
+				`level=WARN msg="di: constructed new instance" type=*dislog.dep name=""`,
+				`level=INFO msg="di: resolved dependency" type=*dislog.dep name=""`,
+			})
+		})
+	})
+}
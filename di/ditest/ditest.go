@@ -0,0 +1,80 @@
+//go:build go1.14
+// +build go1.14
+
+// Package ditest provides helpers for tests which use the di container.
+package ditest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/TsvetanMilanov/go-simple-di/di"
+)
+
+// New creates container with the provided dependencies. It fails the test
+// if the dependencies cannot be registered. The container does not own
+// resources which need to be released, so nothing is closed on t.Cleanup.
+func New(t testing.TB, deps ...*di.Dependency) *di.Container {
+	t.Helper()
+
+	c := di.NewContainer()
+	err := c.Register(deps...)
+	if err != nil {
+		t.Fatalf("ditest: unable to register dependencies: %s", err.Error())
+	}
+
+	return c
+}
+
+// Override replaces the registration which would be injected in fields of
+// the type of real with fake. The type is provided like the out parameter of
+// Resolve, pointer to interface for interfaces:
+//
+//	ditest.Override(t, c, (*Store)(nil), &fakeStore{})
+//	ditest.Override(t, c, (*Clock)(nil), &Clock{Now: fixedNow})
+//
+// It fails the test if the registration cannot be overridden.
+func Override(t testing.TB, c *di.Container, real interface{}, fake interface{}) {
+	t.Helper()
+
+	realType := reflect.TypeOf(real)
+	if realType == nil {
+		t.Fatalf("ditest: the real parameter must not be nil")
+		return
+	}
+
+	if realType.Kind() == reflect.Ptr && realType.Elem().Kind() == reflect.Interface {
+		realType = realType.Elem()
+	}
+
+	err := c.Override(realType, "", &di.Dependency{Value: fake})
+	if err != nil {
+		t.Fatalf("ditest: unable to override %s: %s", realType.String(), err.Error())
+	}
+}
+
+// AssertResolvable fails the test if the marked fields of the registered
+// dependencies cannot be injected. See di.Container.Validate.
+func AssertResolvable(t testing.TB, c *di.Container) {
+	t.Helper()
+
+	err := c.Validate()
+	if err != nil {
+		t.Errorf("ditest: the container is not resolvable:\n%s", err.Error())
+	}
+}
+
+// Snapshot takes snapshot of the registrations of the container and
+// restores it when the test completes. This allows subtests to override
+// registrations of shared container:
+//
+//	t.Run("fake store", func(t *testing.T) {
+//		ditest.Snapshot(t, c)
+//		ditest.Override(t, c, (*Store)(nil), &fakeStore{})
+//	})
+func Snapshot(t testing.TB, c *di.Container) {
+	t.Helper()
+
+	s := c.Snapshot()
+	t.Cleanup(func() { c.Restore(s) })
+}
@@ -0,0 +1,160 @@
+//go:build go1.14
+// +build go1.14
+
+package ditest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/TsvetanMilanov/go-simple-di/di"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type store interface {
+	Get() string
+}
+
+type realStore struct{}
+
+func (s *realStore) Get() string { return "real" }
+
+type fakeStore struct{}
+
+func (s *fakeStore) Get() string { return "fake" }
+
+type service struct {
+	Store store `di:""`
+}
+
+// recordingT records the failures and the cleanups of the helpers.
+type recordingT struct {
+	testing.TB
+	failures []string
+	cleanups []func()
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Fatalf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Cleanup(fn func()) {
+	r.cleanups = append(r.cleanups, fn)
+}
+
+func TestDitest(t *testing.T) {
+	Convey("New", t, func() {
+		Convey("Should register the dependencies.", func() {
+			rt := new(recordingT)
+			c := New(rt, &di.Dependency{Value: new(realStore)})
+
+			So(rt.failures, ShouldBeEmpty)
+			So(c.Registrations(), ShouldHaveLength, 1)
+		})
+		Convey("Should fail for invalid dependencies.", func() {
+			rt := new(recordingT)
+			New(rt, &di.Dependency{})
+
+			So(rt.failures, ShouldResemble, []string{"ditest: unable to register dependencies: the dependency value must not be nil"})
+		})
+	})
+
+	Convey("Override", t, func() {
+		Convey("Should replace the interface implementation.", func() {
+			rt := new(recordingT)
+			c := New(rt, &di.Dependency{Value: new(realStore)}, &di.Dependency{Value: new(service)})
+
+			Override(rt, c, (*store)(nil), new(fakeStore))
+
+			So(rt.failures, ShouldBeEmpty)
+			s := new(service)
+			So(c.Resolve(s), ShouldBeNil)
+			So(s.Store.Get(), ShouldEqual, "fake")
+		})
+		Convey("Should replace pointer registration.", func() {
+			rt := new(recordingT)
+			c := New(rt, &di.Dependency{Value: new(realStore)})
+			fake := new(realStore)
+
+			Override(rt, c, new(realStore), fake)
+
+			So(rt.failures, ShouldBeEmpty)
+			So(c.Registrations()[0].Value, ShouldEqual, fake)
+		})
+		Convey("Should fail for missing registration.", func() {
+			rt := new(recordingT)
+			c := New(rt)
+
+			Override(rt, c, (*store)(nil), new(fakeStore))
+			Override(rt, c, nil, new(fakeStore))
+
+			So(rt.failures, ShouldResemble, []string{
+				"ditest: unable to override ditest.store: unable to find registered dependency: github.com/TsvetanMilanov/go-simple-di/di/ditest.store",
+				"ditest: the real parameter must not be nil",
+			})
+		})
+	})
+
+	Convey("AssertResolvable", t, func() {
+		Convey("Should fail for missing dependencies.", func() {
+			rt := new(recordingT)
+			c := New(rt, &di.Dependency{Value: new(service)})
+
+			AssertResolvable(rt, c)
+
+			So(rt.failures, ShouldResemble, []string{
+				"ditest: the container is not resolvable:\n[*github.com/TsvetanMilanov/go-simple-di/di/ditest.service] unable to find registered dependency: Store",
+			})
+		})
+		Convey("Should pass for resolvable container.", func() {
+			rt := new(recordingT)
+			c := New(rt, &di.Dependency{Value: new(service)}, &di.Dependency{Value: new(realStore)})
+
+			AssertResolvable(rt, c)
+
+			So(rt.failures, ShouldBeEmpty)
+		})
+	})
+
+	Convey("Snapshot", t, func() {
+		Convey("Should restore the registrations on cleanup.", func() {
+			rt := new(recordingT)
+			c := New(rt, &di.Dependency{Value: new(realStore)})
+
+			Snapshot(rt, c)
+			Override(rt, c, (*store)(nil), new(fakeStore))
+			So(rt.cleanups, ShouldHaveLength, 1)
+
+			rt.cleanups[0]()
+
+			var s store
+			So(c.Resolve(&s), ShouldBeNil)
+			So(s.Get(), ShouldEqual, "real")
+		})
+	})
+}
+
+func TestSnapshotSubtests(t *testing.T) {
+	c := New(t, &di.Dependency{Value: new(realStore)}, &di.Dependency{Value: new(service)})
+
+	t.Run("fake", func(t *testing.T) {
+		Snapshot(t, c)
+		Override(t, c, (*store)(nil), new(fakeStore))
+
+		s := new(service)
+		if err := c.Resolve(s); err != nil || s.Store.Get() != "fake" {
+			t.Fatalf("expected fake store, got %v", err)
+		}
+	})
+
+	s := new(service)
+	if err := c.Resolve(s); err != nil || s.Store.Get() != "real" {
+		t.Fatalf("expected real store after the subtest, got %v", err)
+	}
+}
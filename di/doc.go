@@ -0,0 +1,85 @@
+// Package di is simple dependency injection container.
+//
+// The dependencies are registered in the container and injected in the
+// exported fields marked with the di struct tag:
+//
+//	type root struct {
+//		Dep    *dep   `di:""`
+//		Named  *dep   `di:"name=someName"`
+//		Worker worker `di:"optional"`
+//	}
+//
+// Besides pointers and interfaces, plain values such as strings, numbers,
+// slices, maps, funcs and channels can be registered and injected. They are
+// usually distinguished by name:
+//
+//	c.Register(&di.Dependency{Name: "http.port", Value: 8080})
+//
+//	type server struct {
+//		Port int `di:"name=http.port"`
+//	}
+//
+// Struct values cannot be registered or injected, use pointers instead.
+//
+// # Tag grammar
+//
+// The di tag contains comma separated options. Each option is either a flag
+// or key=value pair:
+//
+//	tag    = [ option { "," option } ] .
+//	option = flag | key "=" value .
+//	flag   = "optional" | "required" | "lazy" | "new" | "all" .
+//	key    = "name" | "group" | "default" | "env" | "config" .
+//	value  = bare | quoted .
+//	bare   = non-empty sequence of characters except "," "=" and "'" .
+//	quoted = "'" { character | "\'" | "\\" } "'" .
+//
+// Spaces around the options are ignored and each option can be set once.
+// Values which contain commas, equal signs or quotes should be quoted:
+//
+//	Dep *dep `di:"name='a,b'"`
+//
+// The container supports the following options:
+//
+//	name      inject the dependency registered with the provided name.
+//	optional  leave the field unchanged when the dependency is not registered.
+//	new       inject new instance of the dependency created like ResolveNew
+//	          does. The other fields still share the registered instance.
+//	config    inject the value with the provided key of the ConfigSource set
+//	          with SetConfig, converted to the field type. Strings are parsed
+//	          for numbers, booleans, durations and comma separated slices.
+//	env       inject the value of the provided environment variable converted
+//	          like the config values. The field is left unchanged when the
+//	          variable is not set.
+//	default   for env and config fields, inject the provided value when the
+//	          environment variable or the config key is missing. For the other
+//	          fields, inject the dependency registered with the provided name
+//	          when the dependency of the field is not registered. If it is not
+//	          registered either, pointer fields are injected with new zero
+//	          value instance, plain values keep their zero value and interface
+//	          fields return error. This allows libraries to provide defaults
+//	          which applications override by registering their own
+//	          implementations.
+//	required  return error when the environment variable is not set.
+//
+// The other options are reserved and the container returns error for fields
+// which use them. Invalid tags are reported with the position of the invalid
+// character.
+//
+// # Embedded structs
+//
+// The marked fields of untagged embedded structs and pointers to structs are
+// injected too, which allows sharing common dependencies through base
+// structs. The nil embedded pointers are allocated before injection:
+//
+//	type Base struct {
+//		Logger logger `di:""`
+//	}
+//
+//	type handler struct {
+//		*Base
+//	}
+//
+// The fields promoted through unexported embedded pointers cannot be set.
+// Tagged embedded pointers are injected like the other marked fields.
+package di
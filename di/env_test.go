@@ -0,0 +1,92 @@
+package di
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type envDB struct {
+	DSN     string        `di:"env=DI_TEST_DSN,default=postgres://localhost"`
+	Timeout time.Duration `di:"env=DI_TEST_TIMEOUT"`
+	Port    int           `di:"env=DI_TEST_PORT,required"`
+}
+
+func TestEnv(t *testing.T) {
+	Convey("Env", t, func() {
+		Convey("Should inject the converted environment variables.", func() {
+			os.Setenv("DI_TEST_DSN", "postgres://db")
+			os.Setenv("DI_TEST_TIMEOUT", "5s")
+			os.Setenv("DI_TEST_PORT", "5432")
+			defer os.Unsetenv("DI_TEST_DSN")
+			defer os.Unsetenv("DI_TEST_TIMEOUT")
+			defer os.Unsetenv("DI_TEST_PORT")
+
+			c := NewContainer()
+			res := new(envDB)
+			err := c.Inject(res)
+
+			So(err, ShouldBeNil)
+			So(res.DSN, ShouldEqual, "postgres://db")
+			So(res.Timeout, ShouldEqual, 5*time.Second)
+			So(res.Port, ShouldEqual, 5432)
+		})
+		Convey("Should use the default value for missing variable.", func() {
+			os.Setenv("DI_TEST_PORT", "5432")
+			defer os.Unsetenv("DI_TEST_PORT")
+
+			c := NewContainer()
+			res := &envDB{Timeout: time.Second}
+			err := c.Inject(res)
+
+			So(err, ShouldBeNil)
+			So(res.DSN, ShouldEqual, "postgres://localhost")
+			So(res.Timeout, ShouldEqual, time.Second)
+		})
+		Convey("Should inject empty variables.", func() {
+			os.Setenv("DI_TEST_DSN", "")
+			os.Setenv("DI_TEST_PORT", "5432")
+			defer os.Unsetenv("DI_TEST_DSN")
+			defer os.Unsetenv("DI_TEST_PORT")
+
+			c := NewContainer()
+			res := new(envDB)
+			err := c.Inject(res)
+
+			So(err, ShouldBeNil)
+			So(res.DSN, ShouldBeEmpty)
+		})
+		Convey("Should return error for missing required variable.", func() {
+			c := NewContainer()
+			err := c.Register(&Dependency{Value: new(envDB)})
+			So(err, ShouldBeNil)
+
+			err = c.ResolveAll()
+
+			So(err, ShouldBeError, "[*"+pkgPath+".envDB] missing environment variable 'DI_TEST_PORT' for field Port")
+		})
+		Convey("Should return conversion errors", func() {
+			os.Setenv("DI_TEST_PORT", "port")
+			defer os.Unsetenv("DI_TEST_PORT")
+
+			Convey("of the variable.", func() {
+				c := NewContainer()
+				err := c.Inject(new(envDB))
+
+				So(err, ShouldBeError, "[*"+pkgPath+".envDB] cannot convert environment variable 'DI_TEST_PORT' for field Port: cannot parse 'port' as int")
+			})
+			Convey("of the default value.", func() {
+				type invalidDefault struct {
+					Timeout time.Duration `di:"env=DI_TEST_TIMEOUT,default=soon"`
+				}
+
+				c := NewContainer()
+				err := c.Inject(new(invalidDefault))
+
+				So(err, ShouldBeError, "[*"+pkgPath+".invalidDefault] cannot convert environment variable 'DI_TEST_TIMEOUT' for field Timeout: cannot parse 'soon' as time.Duration")
+			})
+		})
+	})
+}
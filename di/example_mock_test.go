@@ -0,0 +1,56 @@
+package di_test
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/TsvetanMilanov/go-simple-di/di"
+)
+
+type mailer interface {
+	Send(to string) error
+}
+
+type signup struct {
+	Mailer mailer `di:""`
+}
+
+func (s *signup) Register(email string) error {
+	return s.Mailer.Send(email)
+}
+
+// mailerStub records the calls and returns zero values.
+type mailerStub struct {
+	record func(method string, args ...interface{})
+}
+
+func (m *mailerStub) Send(to string) error {
+	m.record("Send", to)
+	return nil
+}
+
+func ExampleContainer_SetMockFactory() {
+	mailerType := reflect.TypeOf(new(mailer)).Elem()
+
+	c := di.NewContainer()
+	c.SetMockFactory(func(t reflect.Type, record func(method string, args ...interface{})) interface{} {
+		if t == mailerType {
+			return &mailerStub{record: record}
+		}
+
+		return nil
+	})
+	c.Register(&di.Dependency{Value: new(signup)})
+
+	// The mailer is not registered, so the stub is injected.
+	res := new(signup)
+	err := c.Resolve(res)
+	if err != nil {
+		panic(err)
+	}
+
+	res.Register("user@example.com")
+	fmt.Println("Calls:", c.MockCalls(mailerType))
+	// Output:
+	// Calls: [{Send [user@example.com]}]
+}
@@ -2,8 +2,10 @@ package di_test
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/TsvetanMilanov/go-simple-di/di"
+	"github.com/TsvetanMilanov/go-simple-di/di/config"
 )
 
 func ExampleContainer_Register() {
@@ -90,6 +92,27 @@ func ExampleContainer_ResolveNew_registered() {
 	// Result: 0
 }
 
+func ExampleContainer_ResolveNew_prototype() {
+	type dep struct {
+		value int
+	}
+
+	c := di.NewContainer()
+	c.Register(
+		&di.Dependency{Value: &dep{value: 400}, Prototype: true},
+	)
+
+	res := new(dep)
+	err := c.ResolveNew(res)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Result:", res.value) // The result is copy of the registered value.
+	// Output:
+	// Result: 400
+}
+
 func ExampleContainer_ResolveNew_notRegistered() {
 	type dep struct {
 		value int
@@ -107,6 +130,115 @@ func ExampleContainer_ResolveNew_notRegistered() {
 	// Result: 0
 }
 
+func ExampleContainer_ResolveNewDeep() {
+	type conn struct {
+		id int
+	}
+	type repo struct {
+		Conn *conn `di:""`
+	}
+	type service struct {
+		Repo *repo `di:""`
+	}
+
+	c := di.NewContainer()
+	c.Register(
+		&di.Dependency{Value: new(service)},
+		&di.Dependency{Value: new(repo)},
+		&di.Dependency{Value: &conn{id: 1}, Shared: true}, // Shared dependencies are not created again.
+	)
+
+	res := new(service)
+	err := c.ResolveNewDeep(res)
+	if err != nil {
+		panic(err)
+	}
+
+	registered := new(service)
+	c.Resolve(registered)
+
+	fmt.Println("New repo:", res.Repo != registered.Repo)
+	fmt.Println("Shared conn:", res.Repo.Conn.id)
+	// Output:
+	// New repo: true
+	// Shared conn: 1
+}
+
+func ExampleContainer_Inject() {
+	type logger struct {
+		prefix string
+	}
+	// The handler is created by the router and it is not registered.
+	type handler struct {
+		path   string
+		Logger *logger `di:""`
+	}
+
+	c := di.NewContainer()
+	c.Register(&di.Dependency{Value: &logger{prefix: "http"}})
+
+	h := &handler{path: "/users"}
+	err := c.Inject(h)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Handler:", h.path, h.Logger.prefix)
+	// Output:
+	// Handler: /users http
+}
+
+func ExampleContainer_Register_plainValues() {
+	type server struct {
+		Port  int      `di:"name=http.port"`
+		Hosts []string `di:"name=http.hosts"`
+	}
+
+	c := di.NewContainer()
+	c.Register(
+		&di.Dependency{Name: "http.port", Value: 8080},
+		&di.Dependency{Name: "http.hosts", Value: []string{"localhost"}},
+	)
+
+	s := new(server)
+	err := c.Inject(s)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Server:", s.Port, s.Hosts)
+	// Output:
+	// Server: 8080 [localhost]
+}
+
+func ExampleContainer_SetConfig() {
+	type db struct {
+		DSN     string        `di:"config=db.dsn"`
+		Timeout time.Duration `di:"config=db.timeout"`
+	}
+
+	cfg, err := config.New(config.Map(map[string]interface{}{
+		"db": map[string]interface{}{"dsn": "postgres://localhost", "timeout": "5s"},
+	}))
+	if err != nil {
+		panic(err)
+	}
+
+	c := di.NewContainer()
+	c.SetConfig(cfg)
+	c.Register(&di.Dependency{Value: new(db)})
+
+	res := new(db)
+	err = c.Resolve(res)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("DB:", res.DSN, res.Timeout)
+	// Output:
+	// DB: postgres://localhost 5s
+}
+
 func ExampleContainer_ResolveAll() {
 	type d1 struct {
 		v string
@@ -146,6 +278,25 @@ func ExampleContainer_ResolveAll() {
 	// Second: d2
 }
 
+func ExampleContainer_Registrations() {
+	type dep struct {
+		value string
+	}
+
+	c := di.NewContainer()
+	c.Register(
+		&di.Dependency{Value: &dep{value: "unnamed"}},
+		&di.Dependency{Value: &dep{value: "named"}, Name: "pickMe"},
+	)
+
+	for _, r := range c.Registrations() {
+		fmt.Printf("Type: %s, Name: %q\n", r.Type, r.Name)
+	}
+	// Output:
+	// Type: *di_test.dep, Name: ""
+	// Type: *di_test.dep, Name: "pickMe"
+}
+
 func ExampleNewContainer() {
 	container := di.NewContainer()
 	fmt.Println(container)
@@ -0,0 +1,119 @@
+package di
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Registration describes a dependency registered in the container.
+type Registration struct {
+	// Name is the name of the dependency. It is empty for unnamed dependencies.
+	Name string
+	// Key is the key used to store the dependency in the container.
+	Key string
+	// Type is the type of the registered value.
+	Type reflect.Type
+	// Value is the registered value.
+	Value interface{}
+	// Resolved reports whether the marked fields of the dependency are populated.
+	Resolved bool
+	// Shared reports whether ResolveNewDeep injects the registered value
+	// instead of creating new instance.
+	Shared bool
+	// Prototype reports whether the new instances of the dependency are
+	// copies of the registered value instead of zero values.
+	Prototype bool
+	// Implements contains the interfaces which are required by marked fields
+	// of the registered dependencies and are implemented by this dependency.
+	Implements []reflect.Type
+	// Module is the name of the module which installed the dependency. It
+	// is empty for dependencies registered directly.
+	Module string
+}
+
+// Registrations returns descriptors of all registered dependencies sorted by key.
+func (c *Container) Registrations() []Registration {
+	interfaces := c.requiredInterfaces()
+	res := make([]Registration, 0, len(c.dependencies))
+	for _, d := range c.dependencies {
+		res = append(res, newRegistration(d, interfaces))
+	}
+
+	sortRegistrations(res)
+	return res
+}
+
+// Has checks if a dependency with the provided type and name is registered.
+// If t is interface, Has checks for registered dependency which implements it.
+func (c *Container) Has(t reflect.Type, name string) bool {
+	if t == nil {
+		return false
+	}
+
+	return c.findDependencyCore(t, name) != nil
+}
+
+// Find returns descriptors of all registered dependencies which can be
+// injected in field with the provided type regardless of their names.
+// If t is interface, Find returns all dependencies which implement it.
+func (c *Container) Find(t reflect.Type) []Registration {
+	res := []Registration{}
+	if t == nil {
+		return res
+	}
+
+	interfaces := c.requiredInterfaces()
+	if t.Kind() == reflect.Interface {
+		for _, d := range c.getImplementations(t) {
+			res = append(res, newRegistration(d, interfaces))
+		}
+	} else {
+		for _, d := range c.dependencies {
+			if d.reflectType == t {
+				res = append(res, newRegistration(d, interfaces))
+			}
+		}
+	}
+
+	sortRegistrations(res)
+	return res
+}
+
+// requiredInterfaces returns the interface types of all marked fields of the
+// registered dependencies.
+func (c *Container) requiredInterfaces() []reflect.Type {
+	seen := make(map[reflect.Type]bool)
+	res := []reflect.Type{}
+	for _, d := range c.dependencies {
+		fields, err := getMarkedFields(d.typeElem)
+		if err != nil {
+			continue
+		}
+
+		for _, f := range fields {
+			if f.injectsDependency() && f.field.Type.Kind() == reflect.Interface && !seen[f.field.Type] {
+				seen[f.field.Type] = true
+				res = append(res, f.field.Type)
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].String() < res[j].String() })
+	return res
+}
+
+func newRegistration(d *dependencyMetadata, interfaces []reflect.Type) Registration {
+	res := d.registration()
+	res.Implements = []reflect.Type{}
+	for _, i := range interfaces {
+		if d.reflectType.Implements(i) {
+			res.Implements = append(res.Implements, i)
+		}
+	}
+
+	return res
+}
+
+func sortRegistrations(r []Registration) {
+	sort.Slice(r, func(i, j int) bool { return r[i].Key < r[j].Key })
+}
@@ -0,0 +1,161 @@
+package di
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIntrospection(t *testing.T) {
+	workerType := reflect.TypeOf((*worker)(nil)).Elem()
+
+	Convey("Introspection", t, func() {
+		Convey("Registrations", func() {
+			Convey("Should describe all registered dependencies.", func() {
+				c := NewContainer()
+				b := &builder{work: "work"}
+				p := &pointerDependency{}
+				err := c.Register(
+					&Dependency{Value: new(secondLevelDependency)},
+					&Dependency{Value: b, Name: "named"},
+					&Dependency{Value: p},
+				)
+				So(err, ShouldBeNil)
+
+				res := c.Registrations()
+
+				So(res, ShouldHaveLength, 3)
+				byType := map[reflect.Type]Registration{}
+				for _, r := range res {
+					byType[r.Type] = r
+				}
+
+				builderReg := byType[reflect.TypeOf(b)]
+				So(builderReg.Name, ShouldEqual, "named")
+				So(builderReg.Value, ShouldEqual, b)
+				So(builderReg.Key, ShouldEqual, getDependencyKey(reflect.TypeOf(b), "named").String())
+				So(builderReg.Key, ShouldEqual, "*"+pkgPath+".builder (name: named)")
+				So(builderReg.Implements, ShouldResemble, []reflect.Type{workerType})
+				So(byType[reflect.TypeOf(p)].Implements, ShouldBeEmpty)
+			})
+			Convey("Should report resolved dependencies.", func() {
+				c := NewContainer()
+				err := c.Register(
+					&Dependency{Value: new(secondLevelDependency)},
+					&Dependency{Value: new(builder)},
+					&Dependency{Value: new(pointerDependency)},
+				)
+				So(err, ShouldBeNil)
+
+				for _, r := range c.Registrations() {
+					So(r.Resolved, ShouldBeFalse)
+				}
+
+				err = c.ResolveAll()
+				So(err, ShouldBeNil)
+
+				for _, r := range c.Registrations() {
+					So(r.Resolved, ShouldBeTrue)
+				}
+			})
+			Convey("Should report the lifetimes of the dependencies.", func() {
+				c := NewContainer()
+				err := c.Register(
+					&Dependency{Value: new(pointerDependency), Shared: true},
+					&Dependency{Value: new(builder), Prototype: true},
+				)
+				So(err, ShouldBeNil)
+
+				res := c.Registrations()
+
+				So(res[0].Shared, ShouldBeFalse)
+				So(res[0].Prototype, ShouldBeTrue)
+				So(res[1].Shared, ShouldBeTrue)
+				So(res[1].Prototype, ShouldBeFalse)
+			})
+			Convey("Should return registrations sorted by key.", func() {
+				c := NewContainer()
+				err := c.Register(
+					&Dependency{Value: new(pointerDependency), Name: "b"},
+					&Dependency{Value: new(pointerDependency), Name: "a"},
+					&Dependency{Value: new(pointerDependency)},
+				)
+				So(err, ShouldBeNil)
+
+				res := c.Registrations()
+
+				So(res, ShouldHaveLength, 3)
+				So(res[0].Name, ShouldEqual, "")
+				So(res[1].Name, ShouldEqual, "a")
+				So(res[2].Name, ShouldEqual, "b")
+			})
+		})
+
+		Convey("Has", func() {
+			c := NewContainer()
+			err := c.Register(
+				&Dependency{Value: new(builder), Name: "named"},
+				&Dependency{Value: new(pointerDependency)},
+			)
+			So(err, ShouldBeNil)
+
+			Convey("Should find registered structs.", func() {
+				So(c.Has(reflect.TypeOf(new(pointerDependency)), ""), ShouldBeTrue)
+				So(c.Has(reflect.TypeOf(new(builder)), "named"), ShouldBeTrue)
+			})
+			Convey("Should find implemented interfaces.", func() {
+				So(c.Has(workerType, "named"), ShouldBeTrue)
+			})
+			Convey("Should NOT find", func() {
+				Convey("dependencies with other names.", func() {
+					So(c.Has(reflect.TypeOf(new(builder)), ""), ShouldBeFalse)
+					So(c.Has(workerType, "other"), ShouldBeFalse)
+				})
+				Convey("not registered dependencies.", func() {
+					So(c.Has(reflect.TypeOf(new(named)), ""), ShouldBeFalse)
+				})
+				Convey("nil types.", func() {
+					So(c.Has(nil, ""), ShouldBeFalse)
+				})
+			})
+		})
+
+		Convey("Find", func() {
+			Convey("Should return all implementations of interface.", func() {
+				c := NewContainer()
+				err := c.Register(
+					&Dependency{Value: new(builder), Name: "first"},
+					&Dependency{Value: new(builder), Name: "second"},
+					&Dependency{Value: new(pointerDependency)},
+				)
+				So(err, ShouldBeNil)
+
+				res := c.Find(workerType)
+
+				So(res, ShouldHaveLength, 2)
+				So(res[0].Name, ShouldEqual, "first")
+				So(res[1].Name, ShouldEqual, "second")
+			})
+			Convey("Should return all registrations of struct type.", func() {
+				c := NewContainer()
+				err := c.Register(
+					&Dependency{Value: new(pointerDependency), Name: "named"},
+					&Dependency{Value: new(pointerDependency)},
+					&Dependency{Value: new(builder)},
+				)
+				So(err, ShouldBeNil)
+
+				res := c.Find(reflect.TypeOf(new(pointerDependency)))
+
+				So(res, ShouldHaveLength, 2)
+			})
+			Convey("Should return empty result when nothing matches.", func() {
+				c := NewContainer()
+
+				So(c.Find(workerType), ShouldBeEmpty)
+				So(c.Find(nil), ShouldBeEmpty)
+			})
+		})
+	})
+}
@@ -0,0 +1,105 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Call is a method call recorded by mock.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// MockFactory creates mock which implements the interface t or returns nil
+// if it cannot mock t. The mock can report its method calls with record to
+// make them available through Container.MockCalls.
+type MockFactory func(t reflect.Type, record func(method string, args ...interface{})) interface{}
+
+type mock struct {
+	meta  *dependencyMetadata
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (m *mock) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// SetMockFactory enables the test mode in which the marked interface fields
+// without registered dependency are injected with mocks created by f. Each
+// interface is mocked once and the mocks are not registered. The optional
+// fields are not mocked.
+//
+// Go cannot create types with methods at runtime, so f must return existing
+// implementations, e.g. generated mocks or stubs which return zero values.
+func (c *Container) SetMockFactory(f MockFactory) {
+	c.mockFactory = f
+	c.mocks = make(map[reflect.Type]*mock)
+}
+
+// MockCalls returns the calls recorded by the mock of the interface t in
+// the order they were made.
+func (c *Container) MockCalls(t reflect.Type) []Call {
+	m, ok := c.mocks[t]
+	if !ok {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// canMock checks if the mock factory can mock the interface t. Unlike
+// mockDependency, it does not keep the mock created by the factory.
+func (c *Container) canMock(t reflect.Type) (bool, error) {
+	if _, ok := c.mocks[t]; ok {
+		return true, nil
+	}
+
+	value := c.mockFactory(t, func(method string, args ...interface{}) {})
+	if value == nil {
+		return false, nil
+	}
+
+	if !reflect.TypeOf(value).Implements(t) {
+		return false, fmt.Errorf("the mock %T does not implement %s", value, t.String())
+	}
+
+	return true, nil
+}
+
+// mockDependency returns the mock of the interface t or nil if there is no
+// mock factory or it cannot mock t.
+func (c *Container) mockDependency(t reflect.Type) (*dependencyMetadata, error) {
+	if c.mockFactory == nil || t.Kind() != reflect.Interface {
+		return nil, nil
+	}
+
+	if m, ok := c.mocks[t]; ok {
+		return m.meta, nil
+	}
+
+	m := new(mock)
+	value := c.mockFactory(t, m.record)
+	if value == nil {
+		return nil, nil
+	}
+
+	if !reflect.TypeOf(value).Implements(t) {
+		return nil, fmt.Errorf("the mock %T does not implement %s", value, t.String())
+	}
+
+	meta, err := newDependencyMetadata(&Dependency{Value: value})
+	if err != nil {
+		return nil, err
+	}
+
+	m.meta = meta
+	c.mocks[t] = m
+	return meta, nil
+}
@@ -0,0 +1,114 @@
+package di
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type mockWorker struct {
+	record func(method string, args ...interface{})
+}
+
+func (m *mockWorker) Work() string {
+	m.record("Work")
+	return ""
+}
+
+type mockDependent struct {
+	Worker   worker             `di:""`
+	Ptr      *pointerDependency `di:""`
+	Optional worker             `di:"optional"`
+}
+
+func workerMockFactory(t reflect.Type, record func(method string, args ...interface{})) interface{} {
+	if t == reflect.TypeOf(new(worker)).Elem() {
+		return &mockWorker{record: record}
+	}
+
+	return nil
+}
+
+func TestMock(t *testing.T) {
+	workerType := reflect.TypeOf(new(worker)).Elem()
+
+	Convey("Mock", t, func() {
+		c := NewContainer()
+		c.SetMockFactory(workerMockFactory)
+
+		Convey("Should inject mocks in the unsatisfied interface fields.", func() {
+			err := c.Register(&Dependency{Value: new(mockDependent)}, &Dependency{Value: new(pointerDependency)})
+			So(err, ShouldBeNil)
+
+			res := new(mockDependent)
+			err = c.Resolve(res)
+
+			So(err, ShouldBeNil)
+			So(res.Worker, ShouldHaveSameTypeAs, new(mockWorker))
+			So(res.Optional, ShouldBeNil)
+			So(c.Registrations(), ShouldHaveLength, 2)
+			So(c.Validate(), ShouldBeNil)
+
+			res.Worker.Work()
+			res.Worker.Work()
+
+			So(c.MockCalls(workerType), ShouldResemble, []Call{{Method: "Work"}, {Method: "Work"}})
+		})
+		Convey("Should reuse the mock of the interface.", func() {
+			type twoWorkers struct {
+				First  worker `di:""`
+				Second worker `di:"name=second"`
+			}
+
+			res := new(twoWorkers)
+			err := c.Inject(res)
+
+			So(err, ShouldBeNil)
+			So(res.First, ShouldEqual, res.Second)
+		})
+		Convey("Should prefer the registered dependencies.", func() {
+			err := c.Register(&Dependency{Value: &builder{work: "real"}}, &Dependency{Value: new(pointerDependency)})
+			So(err, ShouldBeNil)
+
+			res := new(mockDependent)
+			err = c.Inject(res)
+
+			So(err, ShouldBeNil)
+			So(res.Worker.Work(), ShouldEqual, "real")
+			So(c.MockCalls(workerType), ShouldBeNil)
+		})
+		Convey("Should not mock the types which the factory cannot mock.", func() {
+			err := c.Inject(new(mockDependent))
+
+			So(err, ShouldBeError, "[*"+pkgPath+".mockDependent] unable to find registered dependency: Ptr")
+		})
+		Convey("Should return error for mock which does not implement the interface.", func() {
+			c.SetMockFactory(func(t reflect.Type, record func(method string, args ...interface{})) interface{} {
+				return new(pointerDependency)
+			})
+			err := c.Register(&Dependency{Value: new(mockDependent)})
+			So(err, ShouldBeNil)
+
+			err = c.Inject(new(mockDependent))
+
+			So(err, ShouldBeError, "[*"+pkgPath+".mockDependent] the mock *di.pointerDependency does not implement di.worker")
+		})
+		Convey("Should validate the fields which can be mocked without keeping the mocks.", func() {
+			err := c.Register(&Dependency{Value: new(mockDependent)})
+			So(err, ShouldBeNil)
+
+			So(c.Validate(), ShouldBeError, "[*"+pkgPath+".mockDependent] unable to find registered dependency: Ptr")
+			So(c.mocks, ShouldBeEmpty)
+		})
+		Convey("Should not validate the fields which the factory cannot mock.", func() {
+			c.SetMockFactory(func(t reflect.Type, record func(method string, args ...interface{})) interface{} {
+				return nil
+			})
+			err := c.Register(&Dependency{Value: new(mockDependent)}, &Dependency{Value: new(pointerDependency)})
+			So(err, ShouldBeNil)
+
+			So(c.Validate(), ShouldBeError, "[*"+pkgPath+".mockDependent] unable to find registered dependency: Worker")
+		})
+	})
+}
@@ -0,0 +1,132 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Module groups the registrations of a library or a feature, so they can be
+// installed in the container together.
+type Module struct {
+	// Name identifies the module in the container and in the errors.
+	Name string
+	// Dependencies are registered when the module is installed.
+	Dependencies []*Dependency
+	// Providers create the dependencies which need code to be constructed.
+	// They are called after the dependencies of the module are registered.
+	Providers []func(c *Container) (*Dependency, error)
+	// Imports are installed before the module. The modules imported by
+	// several modules are installed once.
+	Imports []*Module
+	// Init hooks are called after all modules passed to Install are
+	// installed, in the order the modules are installed.
+	Init []func(c *Container) error
+}
+
+// Install registers the dependencies of the provided modules and their
+// imports and calls their init hooks. The modules which are already
+// installed are skipped. Install stops at the first error. The
+// registrations of the module which failed are removed, so it can be
+// installed again, but the modules before it remain installed. If an init
+// hook fails, all modules remain installed.
+func (c *Container) Install(modules ...*Module) error {
+	installed := []*Module{}
+	for _, m := range modules {
+		err := c.install(m, nil, &installed)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, m := range installed {
+		for _, init := range m.Init {
+			err := init(c)
+			if err != nil {
+				return fmt.Errorf("[module %s] %s", m.Name, err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Container) install(m *Module, importing []string, installed *[]*Module) error {
+	if m == nil {
+		return errors.New("the module must not be nil")
+	}
+
+	if len(m.Name) == 0 {
+		return errors.New("the module name must not be empty")
+	}
+
+	for _, name := range importing {
+		if name == m.Name {
+			return fmt.Errorf("circular module import: %s -> %s", strings.Join(importing, " -> "), m.Name)
+		}
+	}
+
+	if existing, ok := c.modules[m.Name]; ok {
+		if existing == m {
+			return nil
+		}
+
+		return fmt.Errorf("duplicate module: %s", m.Name)
+	}
+
+	importing = append(importing, m.Name)
+	for _, i := range m.Imports {
+		err := c.install(i, importing, installed)
+		if err != nil {
+			return err
+		}
+	}
+
+	registered, err := c.registerModule(m)
+	if err != nil {
+		for _, d := range registered {
+			c.remove(d)
+		}
+
+		return fmt.Errorf("[module %s] %s", m.Name, err.Error())
+	}
+
+	c.modules[m.Name] = m
+	*installed = append(*installed, m)
+	return nil
+}
+
+// registerModule registers the dependencies of the module and the ones
+// created by its providers. It returns the registered dependencies even if
+// it fails, so they can be removed.
+func (c *Container) registerModule(m *Module) ([]*dependencyMetadata, error) {
+	registered := []*dependencyMetadata{}
+	for _, d := range m.Dependencies {
+		meta, err := c.register(d, m.Name)
+		if err != nil {
+			return registered, err
+		}
+
+		registered = append(registered, meta)
+	}
+
+	for _, p := range m.Providers {
+		d, err := p(c)
+		if err == nil && d == nil {
+			err = errors.New("the provider returned nil dependency")
+		}
+
+		if err != nil {
+			return registered, err
+		}
+
+		meta, err := c.register(d, m.Name)
+		if err != nil {
+			return registered, err
+		}
+
+		registered = append(registered, meta)
+	}
+
+	return registered, nil
+}
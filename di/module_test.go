@@ -0,0 +1,147 @@
+package di
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestModule(t *testing.T) {
+	Convey("Install", t, func() {
+		c := NewContainer()
+		calls := []string{}
+		base := &Module{
+			Name:         "base",
+			Dependencies: []*Dependency{{Value: new(pointerDependency)}},
+			Init: []func(c *Container) error{func(c *Container) error {
+				calls = append(calls, "base")
+				return nil
+			}},
+		}
+
+		Convey("Should install the modules and their imports.", func() {
+			app := &Module{
+				Name:         "app",
+				Dependencies: []*Dependency{{Value: new(firstLevelDependency)}},
+				Providers: []func(c *Container) (*Dependency, error){func(c *Container) (*Dependency, error) {
+					p := new(pointerDependency)
+					err := c.Resolve(p)
+					return &Dependency{Value: &builder{work: "provided"}}, err
+				}},
+				Imports: []*Module{base},
+				Init: []func(c *Container) error{func(c *Container) error {
+					calls = append(calls, "app")
+					return c.ResolveAll()
+				}},
+			}
+			other := &Module{Name: "other", Dependencies: []*Dependency{{Value: new(secondLevelDependency)}}, Imports: []*Module{base}}
+
+			err := c.Install(app, other)
+
+			So(err, ShouldBeNil)
+			So(calls, ShouldResemble, []string{"base", "app"})
+			registrations := c.Registrations()
+			So(registrations, ShouldHaveLength, 4)
+			So(registrations[0].Module, ShouldEqual, "app")
+			So(registrations[1].Module, ShouldEqual, "app")
+			So(registrations[2].Module, ShouldEqual, "base")
+			So(registrations[3].Module, ShouldEqual, "other")
+			So(registrations[1].Resolved, ShouldBeTrue)
+		})
+		Convey("Should skip the installed modules.", func() {
+			So(c.Install(base), ShouldBeNil)
+			So(c.Install(base), ShouldBeNil)
+
+			So(calls, ShouldResemble, []string{"base"})
+			So(c.Registrations(), ShouldHaveLength, 1)
+		})
+		Convey("Should keep the module of the overridden registrations.", func() {
+			So(c.Install(base), ShouldBeNil)
+
+			err := c.Replace(&Dependency{Value: new(pointerDependency)})
+
+			So(err, ShouldBeNil)
+			So(c.Registrations()[0].Module, ShouldEqual, "base")
+		})
+		Convey("Should install again the module which failed.", func() {
+			failed := false
+			m := &Module{
+				Name:         "retry",
+				Dependencies: []*Dependency{{Value: new(pointerDependency)}},
+				Providers: []func(c *Container) (*Dependency, error){func(c *Container) (*Dependency, error) {
+					if !failed {
+						failed = true
+						return nil, errors.New("no connection")
+					}
+
+					return &Dependency{Value: new(builder)}, nil
+				}},
+				Init: []func(c *Container) error{func(c *Container) error {
+					calls = append(calls, "retry")
+					return nil
+				}},
+			}
+
+			So(c.Install(m), ShouldBeError, "[module retry] no connection")
+			So(c.Registrations(), ShouldBeEmpty)
+
+			So(c.Install(m), ShouldBeNil)
+			So(c.Registrations(), ShouldHaveLength, 2)
+			So(calls, ShouldResemble, []string{"retry"})
+		})
+		Convey("Should return error for", func() {
+			Convey("duplicate module.", func() {
+				So(c.Install(base), ShouldBeNil)
+
+				err := c.Install(&Module{Name: "base"})
+
+				So(err, ShouldBeError, "duplicate module: base")
+			})
+			Convey("circular imports.", func() {
+				first := &Module{Name: "first"}
+				second := &Module{Name: "second", Imports: []*Module{first}}
+				first.Imports = []*Module{second}
+
+				err := c.Install(first)
+
+				So(err, ShouldBeError, "circular module import: first -> second -> first")
+			})
+			Convey("conflicting registrations.", func() {
+				err := c.Install(base, &Module{Name: "conflict", Dependencies: []*Dependency{{Value: new(pointerDependency)}}})
+
+				So(err, ShouldBeError, "[module conflict] duplicate dependency: *"+pkgPath+".pointerDependency (registered by module base)")
+
+				err = c.Register(&Dependency{Value: new(pointerDependency)})
+
+				So(err, ShouldBeError, "duplicate dependency: *"+pkgPath+".pointerDependency (registered by module base)")
+			})
+			Convey("invalid modules.", func() {
+				So(c.Install(nil), ShouldBeError, "the module must not be nil")
+				So(c.Install(&Module{}), ShouldBeError, "the module name must not be empty")
+				So(c.Install(&Module{Name: "invalid", Dependencies: []*Dependency{{}}}), ShouldBeError,
+					"[module invalid] the dependency value must not be nil")
+			})
+			Convey("failed providers.", func() {
+				err := c.Install(&Module{Name: "provider", Providers: []func(c *Container) (*Dependency, error){
+					func(c *Container) (*Dependency, error) { return nil, nil },
+				}})
+
+				So(err, ShouldBeError, "[module provider] the provider returned nil dependency")
+
+				err = c.Install(&Module{Name: "failing", Providers: []func(c *Container) (*Dependency, error){
+					func(c *Container) (*Dependency, error) { return nil, errors.New("no connection") },
+				}})
+
+				So(err, ShouldBeError, "[module failing] no connection")
+			})
+			Convey("failed init hooks.", func() {
+				err := c.Install(&Module{Name: "init", Init: []func(c *Container) error{
+					func(c *Container) error { return errors.New("not ready") },
+				}})
+
+				So(err, ShouldBeError, "[module init] not ready")
+			})
+		})
+	})
+}
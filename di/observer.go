@@ -0,0 +1,55 @@
+package di
+
+import (
+	"reflect"
+	"time"
+)
+
+// Observer receives notifications about the activities of the container.
+// The Implements field of the provided registrations is not populated.
+type Observer interface {
+	// OnRegister is called after a dependency is registered.
+	OnRegister(r Registration)
+	// OnResolveStart is called before the marked fields of a dependency
+	// are populated.
+	OnResolveStart(r Registration)
+	// OnResolveEnd is called after the marked fields of a dependency are
+	// populated or the resolving fails. The duration includes the resolving
+	// of the nested dependencies.
+	OnResolveEnd(r Registration, duration time.Duration, err error)
+	// OnInject is called after dep is set to the field of parent.
+	OnInject(parent Registration, field reflect.StructField, dep Registration)
+	// OnConstruct is called after the container creates new instance.
+	OnConstruct(r Registration)
+}
+
+// NopObserver is Observer which ignores all notifications.
+// It can be embedded in observers which need only some of the notifications.
+type NopObserver struct{}
+
+// OnRegister implements Observer.
+func (NopObserver) OnRegister(r Registration) {}
+
+// OnResolveStart implements Observer.
+func (NopObserver) OnResolveStart(r Registration) {}
+
+// OnResolveEnd implements Observer.
+func (NopObserver) OnResolveEnd(r Registration, duration time.Duration, err error) {}
+
+// OnInject implements Observer.
+func (NopObserver) OnInject(parent Registration, field reflect.StructField, dep Registration) {}
+
+// OnConstruct implements Observer.
+func (NopObserver) OnConstruct(r Registration) {}
+
+// AddObserver adds the provided observers to the container.
+// The observers are notified in the order they are added.
+func (c *Container) AddObserver(observers ...Observer) {
+	c.observers = append(c.observers, observers...)
+}
+
+func (c *Container) notify(fn func(o Observer)) {
+	for _, o := range c.observers {
+		fn(o)
+	}
+}
@@ -0,0 +1,102 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type recordingObserver struct {
+	NopObserver
+	events []string
+	errors []error
+}
+
+func (o *recordingObserver) OnRegister(r Registration) {
+	o.events = append(o.events, fmt.Sprintf("register %s", r.Type))
+}
+
+func (o *recordingObserver) OnResolveStart(r Registration) {
+	o.events = append(o.events, fmt.Sprintf("start %s", r.Type))
+}
+
+func (o *recordingObserver) OnResolveEnd(r Registration, duration time.Duration, err error) {
+	o.events = append(o.events, fmt.Sprintf("end %s", r.Type))
+	o.errors = append(o.errors, err)
+}
+
+func (o *recordingObserver) OnInject(parent Registration, field reflect.StructField, dep Registration) {
+	o.events = append(o.events, fmt.Sprintf("inject %s.%s %s", parent.Type, field.Name, dep.Type))
+}
+
+func (o *recordingObserver) OnConstruct(r Registration) {
+	o.events = append(o.events, fmt.Sprintf("construct %s", r.Type))
+}
+
+func TestObserver(t *testing.T) {
+	Convey("Observer", t, func() {
+		c := NewContainer()
+		o := new(recordingObserver)
+		c.AddObserver(o)
+
+		Convey("Should be notified for registrations.", func() {
+			err := c.Register(
+				&Dependency{Value: new(pointerDependency)},
+				&Dependency{Value: new(builder)},
+			)
+
+			So(err, ShouldBeNil)
+			So(o.events, ShouldResemble, []string{
+				"register *di.pointerDependency",
+				"register *di.builder",
+			})
+		})
+		Convey("Should be notified for resolving and injections.", func() {
+			err := c.Register(
+				&Dependency{Value: new(secondLevelDependency)},
+				&Dependency{Value: new(pointerDependency)},
+				&Dependency{Value: new(builder)},
+			)
+			So(err, ShouldBeNil)
+			o.events = nil
+
+			err = c.Resolve(new(secondLevelDependency))
+
+			So(err, ShouldBeNil)
+			So(o.events, ShouldResemble, []string{
+				"start *di.secondLevelDependency",
+				"start *di.pointerDependency",
+				"end *di.pointerDependency",
+				"inject *di.secondLevelDependency.PointerThirdLevel *di.pointerDependency",
+				"start *di.builder",
+				"end *di.builder",
+				"inject *di.secondLevelDependency.InterfaceThirdLevel *di.builder",
+				"end *di.secondLevelDependency",
+			})
+			So(o.errors, ShouldResemble, []error{nil, nil, nil})
+		})
+		Convey("Should be notified for resolve errors.", func() {
+			err := c.Register(&Dependency{Value: new(firstLevelDependency)})
+			So(err, ShouldBeNil)
+
+			err = c.ResolveAll()
+
+			So(err, ShouldNotBeNil)
+			So(o.errors, ShouldHaveLength, 1)
+			So(o.errors[0], ShouldEqual, err)
+		})
+		Convey("Should be notified for new instances.", func() {
+			err := c.ResolveNew(new(pointerDependency))
+
+			So(err, ShouldBeNil)
+			So(o.events, ShouldResemble, []string{
+				"construct *di.pointerDependency",
+				"start *di.pointerDependency",
+				"end *di.pointerDependency",
+			})
+		})
+	})
+}
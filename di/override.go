@@ -0,0 +1,254 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Replace replaces the registrations with the keys of the provided
+// dependencies, which are the type of the value and the name. It returns
+// error if there is no such registration.
+//
+// The dependencies which are already resolved are not changed, but the ones
+// which depend on the replaced registrations are injected again by the next
+// resolve.
+func (c *Container) Replace(deps ...*Dependency) error {
+	for _, d := range deps {
+		meta, err := newDependencyMetadata(d)
+		if err != nil {
+			return err
+		}
+
+		old, ok := c.dependencies[meta.key]
+		if !ok {
+			return fmt.Errorf("unable to find registered dependency: %s", meta.key)
+		}
+
+		meta.module = old.module
+		c.remove(old)
+		c.add(meta)
+	}
+
+	return nil
+}
+
+// Override replaces the registration which would be injected in field of
+// type t marked with the provided name with the dependency d. The type of d
+// can differ from t, e.g. test double which implements interface t. If d
+// has no name, it gets the name of the replaced registration.
+//
+// Like Replace, the dependencies which depend on the replaced registration
+// are injected again by the next resolve.
+func (c *Container) Override(t reflect.Type, name string, d *Dependency) error {
+	old, err := c.findRegistration(t, name)
+	if err != nil {
+		return err
+	}
+
+	if len(d.Name) == 0 {
+		named := *d
+		named.Name = old.Name
+		d = &named
+	}
+
+	meta, err := newDependencyMetadata(d)
+	if err != nil {
+		return err
+	}
+
+	if existing, ok := c.dependencies[meta.key]; ok && existing != old {
+		return fmt.Errorf("duplicate dependency: %s", meta.key)
+	}
+
+	meta.module = old.module
+	c.remove(old)
+	c.add(meta)
+	return nil
+}
+
+// Unregister removes the registration which would be injected in field of
+// type t marked with the provided name. The fields which are injected with
+// it are set to their zero value and their dependencies are injected again
+// by the next resolve.
+func (c *Container) Unregister(t reflect.Type, name string) error {
+	old, err := c.findRegistration(t, name)
+	if err != nil {
+		return err
+	}
+
+	for dep, f := range c.dependents[old] {
+		field := fieldByIndex(dep.parent.valueElem, f.index)
+		if isSameValue(field, old.reflectValue) {
+			field.Set(reflect.Zero(field.Type()))
+		}
+	}
+
+	c.remove(old)
+	return nil
+}
+
+// findRegistration returns the single registration which would be injected
+// in field of type t marked with the provided name.
+func (c *Container) findRegistration(t reflect.Type, name string) (*dependencyMetadata, error) {
+	if t == nil {
+		return nil, errors.New("the type must not be nil")
+	}
+
+	candidates := c.findCandidates(t, name)
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("unable to find registered dependency: %s", getDependencyKey(t, name))
+	case 1:
+		return candidates[0], nil
+	}
+
+	keys := make([]string, len(candidates))
+	for i, d := range candidates {
+		keys[i] = d.key.String()
+	}
+
+	return nil, fmt.Errorf("ambiguous dependency: %s can be satisfied by %s",
+		getDependencyKey(t, name), strings.Join(keys, ", "))
+}
+
+// remove deletes the registration from the container and its indexes and
+// marks its dependents for injection on the next resolve.
+func (c *Container) remove(d *dependencyMetadata) {
+	c.invalidateDependents(d, make(map[*dependencyMetadata]bool))
+	delete(c.dependents, d)
+	c.removeDependencies(d)
+	c.unindex(d)
+}
+
+// unindex deletes the registration from the registered dependencies and the
+// indexed interfaces.
+func (c *Container) unindex(d *dependencyMetadata) {
+	if c.dependencies[d.key] == d {
+		delete(c.dependencies, d.key)
+	}
+
+	for t, deps := range c.implementations {
+		for i, impl := range deps {
+			if impl == d {
+				c.implementations[t] = append(deps[:i:i], deps[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// removeDependencies deletes the fields of d from the dependents index.
+func (c *Container) removeDependencies(d *dependencyMetadata) {
+	for _, fields := range c.dependents {
+		for dep := range fields {
+			if dep.parent == d {
+				delete(fields, dep)
+			}
+		}
+	}
+}
+
+// invalidateDependents marks the dependencies which depend on d, directly
+// or through other dependencies, for injection on the next resolve.
+func (c *Container) invalidateDependents(d *dependencyMetadata, visited map[*dependencyMetadata]bool) {
+	for dep := range c.dependents[d] {
+		if visited[dep.parent] {
+			continue
+		}
+
+		visited[dep.parent] = true
+		dep.parent.complete = false
+		dep.parent.timing = nil
+		c.invalidateDependents(dep.parent, visited)
+	}
+}
+
+// addDependent records that the field of the registered dependency parent is
+// injected with the registered dependency d.
+func (c *Container) addDependent(d, parent *dependencyMetadata, f markedField) {
+	if c.dependencies[d.key] != d || c.dependencies[parent.key] != parent {
+		return
+	}
+
+	fields, ok := c.dependents[d]
+	if !ok {
+		fields = make(map[dependent]markedField)
+		c.dependents[d] = fields
+	}
+
+	fields[dependent{parent: parent, field: f.name}] = f
+}
+
+// isSameValue checks if the field holds the value. Funcs, maps and slices
+// are compared by their pointers and the other values which are not
+// comparable, like arrays of slices, are compared deeply.
+func isSameValue(field, value reflect.Value) bool {
+	if field.Kind() == reflect.Interface {
+		field = field.Elem()
+	}
+
+	if !field.IsValid() || field.Type() != value.Type() {
+		return false
+	}
+
+	switch field.Kind() {
+	case reflect.Func, reflect.Map:
+		return field.Pointer() == value.Pointer()
+	case reflect.Slice:
+		return field.Pointer() == value.Pointer() && field.Len() == value.Len()
+	}
+
+	if !field.Type().Comparable() {
+		return reflect.DeepEqual(field.Interface(), value.Interface())
+	}
+
+	return field.Interface() == value.Interface()
+}
+
+// Snapshot contains the registrations and the installed modules of container
+// at some point.
+type Snapshot struct {
+	dependencies map[dependencyKey]*dependencyMetadata
+	modules      map[string]*Module
+}
+
+// Snapshot returns the current registrations of the container. Use Restore
+// to roll back the registrations added or overridden after the snapshot.
+func (c *Container) Snapshot() *Snapshot {
+	modules := make(map[string]*Module, len(c.modules))
+	for name, m := range c.modules {
+		modules[name] = m
+	}
+
+	return &Snapshot{dependencies: copyDependencies(c.dependencies), modules: modules}
+}
+
+// Restore rolls back the registrations of the container to the snapshot.
+// It does not change the resolved dependencies but all of them are injected
+// again by the next resolve.
+func (c *Container) Restore(s *Snapshot) {
+	c.dependencies = copyDependencies(s.dependencies)
+	c.modules = make(map[string]*Module, len(s.modules))
+	for name, m := range s.modules {
+		c.modules[name] = m
+	}
+
+	for _, d := range c.dependencies {
+		d.complete = false
+		d.timing = nil
+	}
+
+	c.implementations = make(map[reflect.Type][]*dependencyMetadata)
+	c.dependents = make(map[*dependencyMetadata]map[dependent]markedField)
+}
+
+func copyDependencies(deps map[dependencyKey]*dependencyMetadata) map[dependencyKey]*dependencyMetadata {
+	res := make(map[dependencyKey]*dependencyMetadata, len(deps))
+	for k, d := range deps {
+		res[k] = d
+	}
+
+	return res
+}
@@ -0,0 +1,297 @@
+package di
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOverride(t *testing.T) {
+	workerType := reflect.TypeOf(new(worker)).Elem()
+
+	Convey("Override", t, func() {
+		Convey("Should replace the registration of pointer type.", func() {
+			c := NewContainer()
+			err := c.Register(&Dependency{Value: &pointerDependency{value: 1}})
+			So(err, ShouldBeNil)
+
+			fake := &pointerDependency{value: 2}
+			err = c.Override(reflect.TypeOf(fake), "", &Dependency{Value: fake})
+			So(err, ShouldBeNil)
+
+			res := new(pointerDependency)
+			err = c.Resolve(res)
+
+			So(err, ShouldBeNil)
+			So(res.value, ShouldEqual, 2)
+			So(c.Registrations(), ShouldHaveLength, 1)
+		})
+		Convey("Should replace the implementation of interface.", func() {
+			c := NewContainer()
+			err := c.Register(&Dependency{Name: "real", Value: &builder{work: "real"}})
+			So(err, ShouldBeNil)
+
+			var w worker
+			err = c.Resolve(&w)
+			So(err, ShouldBeNil)
+			So(w.Work(), ShouldEqual, "real")
+
+			err = c.Override(workerType, "", &Dependency{Value: &fakeWorker{}})
+			So(err, ShouldBeNil)
+
+			err = c.Resolve(&w)
+
+			So(err, ShouldBeNil)
+			So(w.Work(), ShouldEqual, "fake")
+			So(c.Registrations(), ShouldHaveLength, 1)
+			So(c.Registrations()[0].Name, ShouldEqual, "real")
+		})
+		Convey("Should inject the override in resolved dependencies.", func() {
+			c := NewContainer()
+			root := new(secondLevelDependency)
+			err := c.Register(
+				&Dependency{Value: root},
+				&Dependency{Value: new(pointerDependency)},
+				&Dependency{Value: &builder{work: "real"}},
+			)
+			So(err, ShouldBeNil)
+			So(c.ResolveAll(), ShouldBeNil)
+			So(root.InterfaceThirdLevel.Work(), ShouldEqual, "real")
+
+			err = c.Override(workerType, "", &Dependency{Value: &fakeWorker{}})
+			So(err, ShouldBeNil)
+			registrations := c.Registrations()
+			So(registrations[1].Type, ShouldEqual, reflect.TypeOf(new(pointerDependency)))
+			So(registrations[1].Resolved, ShouldBeTrue)
+			So(registrations[2].Type, ShouldEqual, reflect.TypeOf(root))
+			So(registrations[2].Resolved, ShouldBeFalse)
+
+			err = c.ResolveAll()
+
+			So(err, ShouldBeNil)
+			So(root.InterfaceThirdLevel.Work(), ShouldEqual, "fake")
+		})
+		Convey("Should return error for", func() {
+			c := NewContainer()
+			err := c.Register(
+				&Dependency{Name: "first", Value: &builder{}},
+				&Dependency{Name: "second", Value: &builder{}},
+				&Dependency{Value: new(pointerDependency)},
+			)
+			So(err, ShouldBeNil)
+
+			Convey("missing registration.", func() {
+				err := c.Override(reflect.TypeOf(new(pointerDependency)), "missing", &Dependency{Value: new(pointerDependency)})
+
+				So(err, ShouldBeError, "unable to find registered dependency: *"+pkgPath+".pointerDependency (name: missing)")
+			})
+			Convey("ambiguous registration.", func() {
+				err := c.Override(workerType, "", &Dependency{Value: &fakeWorker{}})
+
+				So(err, ShouldBeError, "ambiguous dependency: "+pkgPath+".worker can be satisfied by *"+pkgPath+
+					".builder (name: first), *"+pkgPath+".builder (name: second)")
+			})
+			Convey("duplicate registration.", func() {
+				err := c.Override(workerType, "first", &Dependency{Name: "second", Value: &builder{}})
+
+				So(err, ShouldBeError, "duplicate dependency: *"+pkgPath+".builder (name: second)")
+			})
+			Convey("invalid dependency.", func() {
+				err := c.Override(workerType, "first", &Dependency{})
+
+				So(err, ShouldBeError, "the dependency value must not be nil")
+				So(c.Registrations(), ShouldHaveLength, 3)
+			})
+			Convey("nil type.", func() {
+				var w worker
+				err := c.Override(reflect.TypeOf(w), "", &Dependency{Value: &builder{}})
+
+				So(err, ShouldBeError, "the type must not be nil")
+			})
+		})
+	})
+
+	Convey("Replace", t, func() {
+		Convey("Should replace the registrations with the same keys.", func() {
+			c := NewContainer()
+			root := new(firstLevelDependency)
+			err := c.Register(
+				&Dependency{Value: root},
+				&Dependency{Value: new(secondLevelDependency)},
+				&Dependency{Value: &pointerDependency{value: 1}},
+				&Dependency{Name: "port", Value: 80},
+				&Dependency{Value: &builder{work: "real"}},
+			)
+			So(err, ShouldBeNil)
+			So(c.ResolveAll(), ShouldBeNil)
+
+			replacement := &pointerDependency{value: 2}
+			err = c.Replace(&Dependency{Value: replacement}, &Dependency{Name: "port", Value: 8080})
+			So(err, ShouldBeNil)
+
+			So(c.Registrations()[0].Resolved, ShouldBeTrue)
+			err = c.Resolve(new(firstLevelDependency))
+
+			So(err, ShouldBeNil)
+			So(root.Second.PointerThirdLevel, ShouldEqual, replacement)
+			var port int
+			So(c.ResolveByName("port", &port), ShouldBeNil)
+			So(port, ShouldEqual, 8080)
+		})
+		Convey("Should return error for missing registration.", func() {
+			c := NewContainer()
+			err := c.Replace(&Dependency{Value: new(pointerDependency)})
+
+			So(err, ShouldBeError, "unable to find registered dependency: *"+pkgPath+".pointerDependency")
+		})
+	})
+
+	Convey("Unregister", t, func() {
+		type optional struct {
+			Worker worker            `di:"optional"`
+			Func   func() string     `di:"optional"`
+			Map    map[string]string `di:"optional"`
+		}
+
+		Convey("Should remove the registration and clear the injected fields.", func() {
+			c := NewContainer()
+			root := new(optional)
+			err := c.Register(
+				&Dependency{Value: root},
+				&Dependency{Value: &builder{work: "real"}},
+				&Dependency{Value: func() string { return "func" }},
+				&Dependency{Value: map[string]string{}},
+			)
+			So(err, ShouldBeNil)
+			So(c.ResolveAll(), ShouldBeNil)
+			So(root.Worker, ShouldNotBeNil)
+
+			So(c.Unregister(workerType, ""), ShouldBeNil)
+			So(c.Unregister(reflect.TypeOf(root.Func), ""), ShouldBeNil)
+			So(c.Unregister(reflect.TypeOf(root.Map), ""), ShouldBeNil)
+
+			So(root.Worker, ShouldBeNil)
+			So(root.Func, ShouldBeNil)
+			So(root.Map, ShouldBeNil)
+			So(c.Registrations(), ShouldHaveLength, 1)
+			So(c.Registrations()[0].Resolved, ShouldBeFalse)
+
+			var w worker
+			So(c.Resolve(&w), ShouldBeError, "unable to find registered dependency: *"+pkgPath+".worker")
+			So(c.ResolveAll(), ShouldBeNil)
+		})
+		Convey("Should clear the fields with values which are not comparable.", func() {
+			type withArray struct {
+				Array [1][]int `di:""`
+			}
+
+			c := NewContainer()
+			root := new(withArray)
+			array := [1][]int{{1}}
+			err := c.Register(&Dependency{Value: root}, &Dependency{Value: array})
+			So(err, ShouldBeNil)
+			So(c.ResolveAll(), ShouldBeNil)
+
+			So(c.Unregister(reflect.TypeOf(array), ""), ShouldBeNil)
+
+			So(root.Array, ShouldResemble, [1][]int{})
+		})
+		Convey("Should invalidate the dependents transitively.", func() {
+			c := NewContainer()
+			err := c.Register(
+				&Dependency{Value: new(firstLevelDependency)},
+				&Dependency{Value: new(secondLevelDependency)},
+				&Dependency{Value: new(pointerDependency)},
+				&Dependency{Value: &builder{}},
+			)
+			So(err, ShouldBeNil)
+			So(c.ResolveAll(), ShouldBeNil)
+
+			So(c.Unregister(reflect.TypeOf(new(pointerDependency)), ""), ShouldBeNil)
+
+			err = c.Resolve(new(firstLevelDependency))
+			So(err, ShouldBeError, "[*"+pkgPath+".firstLevelDependency] [*"+pkgPath+".secondLevelDependency] unable to find registered dependency: PointerThirdLevel")
+		})
+		Convey("Should return error for missing registration.", func() {
+			c := NewContainer()
+			err := c.Unregister(workerType, "missing")
+
+			So(err, ShouldBeError, "unable to find registered dependency: "+pkgPath+".worker (name: missing)")
+		})
+		Convey("Should return error for nil type.", func() {
+			c := NewContainer()
+			err := c.Unregister(nil, "")
+
+			So(err, ShouldBeError, "the type must not be nil")
+		})
+	})
+
+	Convey("Snapshot", t, func() {
+		Convey("Should restore the registrations.", func() {
+			c := NewContainer()
+			err := c.Register(&Dependency{Value: &builder{work: "real"}})
+			So(err, ShouldBeNil)
+
+			s := c.Snapshot()
+			err = c.Override(workerType, "", &Dependency{Value: &fakeWorker{}})
+			So(err, ShouldBeNil)
+			err = c.Register(&Dependency{Value: new(pointerDependency)})
+			So(err, ShouldBeNil)
+
+			var w worker
+			So(c.Resolve(&w), ShouldBeNil)
+			So(w.Work(), ShouldEqual, "fake")
+
+			c.Restore(s)
+
+			So(c.Resolve(&w), ShouldBeNil)
+			So(w.Work(), ShouldEqual, "real")
+			So(c.Registrations(), ShouldHaveLength, 1)
+		})
+	})
+
+	Convey("Validate", t, func() {
+		Convey("Should return nil for valid graph.", func() {
+			type valid struct {
+				Ptr      *pointerDependency `di:""`
+				Optional worker             `di:"optional"`
+				New      *builder           `di:"new"`
+				Default  *builder           `di:"default=''"`
+				Config   string             `di:"config=missing"`
+			}
+
+			c := NewContainer()
+			err := c.Register(&Dependency{Value: new(valid)}, &Dependency{Value: new(pointerDependency)})
+			So(err, ShouldBeNil)
+
+			So(c.Validate(), ShouldBeNil)
+			So(c.Registrations()[0].Resolved, ShouldBeFalse)
+		})
+		Convey("Should report all invalid fields.", func() {
+			type invalid struct {
+				Missing *pointerDependency `di:""`
+				Worker  worker             `di:"default=noop"`
+				New     worker             `di:"new"`
+				unexp   *pointerDependency `di:""`
+			}
+			type invalidTag struct {
+				Ptr *pointerDependency `di:"lazy"`
+			}
+
+			c := NewContainer()
+			err := c.Register(&Dependency{Value: new(invalid)}, &Dependency{Value: new(invalidTag)})
+			So(err, ShouldBeNil)
+
+			So(c.Validate(), ShouldBeError, "[*"+pkgPath+".invalid] unable to find registered dependency: Missing\n"+
+				"[*"+pkgPath+".invalid] unable to find registered dependency: Worker\n"+
+				"[*"+pkgPath+".invalid] unable to find registered dependency: New\n"+
+				"[*"+pkgPath+".invalid] cannot set field unexp\n"+
+				"[*"+pkgPath+".invalidTag] unsupported di tag option 'lazy' for field Ptr")
+		})
+	})
+}
+
+type fakeWorker struct{}
+
+func (f *fakeWorker) Work() string { return "fake" }
@@ -0,0 +1,172 @@
+package di
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// StartupReport contains the time spent resolving the registered dependencies.
+// The registered dependencies are created before they are registered, so
+// the report measures the time spent populating their marked fields.
+type StartupReport struct {
+	// Entries contains the resolved dependencies in the order their
+	// resolving started.
+	Entries []StartupEntry
+	// CriticalPath contains the chain of nested resolves which took the most
+	// time, starting from the slowest root dependency.
+	CriticalPath []StartupEntry
+}
+
+// StartupEntry contains the time spent resolving single dependency.
+type StartupEntry struct {
+	Registration Registration
+	// Start is the time when the resolving started.
+	Start time.Time
+	// Duration is the time spent resolving the dependency including the
+	// resolving of its nested dependencies.
+	Duration time.Duration
+	// SelfDuration is the time spent resolving the dependency excluding
+	// the resolving of its nested dependencies.
+	SelfDuration time.Duration
+	// Depth is the number of dependencies which were resolving when the
+	// resolving of this dependency started.
+	Depth int
+}
+
+// StartupReport returns the time spent resolving each registered dependency.
+// Dependencies which are not resolved are not included in the report.
+func (c *Container) StartupReport() *StartupReport {
+	entries := make(map[*dependencyMetadata]*StartupEntry)
+	children := make(map[*dependencyMetadata][]*dependencyMetadata)
+	for _, d := range c.dependencies {
+		if d.timing == nil {
+			continue
+		}
+
+		entries[d] = &StartupEntry{
+			Registration: d.registration(),
+			Start:        d.timing.start,
+			Duration:     d.timing.duration,
+			SelfDuration: d.timing.duration,
+		}
+	}
+
+	roots := []*dependencyMetadata{}
+	for d := range entries {
+		parent := d.timing.parent
+		if _, ok := entries[parent]; !ok {
+			roots = append(roots, d)
+			continue
+		}
+
+		children[parent] = append(children[parent], d)
+		entries[parent].SelfDuration -= d.timing.duration
+	}
+
+	var setDepth func(d *dependencyMetadata, depth int)
+	setDepth = func(d *dependencyMetadata, depth int) {
+		entries[d].Depth = depth
+		for _, child := range children[d] {
+			setDepth(child, depth+1)
+		}
+	}
+
+	for _, r := range roots {
+		setDepth(r, 0)
+	}
+
+	res := &StartupReport{Entries: []StartupEntry{}, CriticalPath: []StartupEntry{}}
+	for _, e := range entries {
+		res.Entries = append(res.Entries, *e)
+	}
+
+	sort.SliceStable(res.Entries, func(i, j int) bool {
+		return res.Entries[i].Start.Before(res.Entries[j].Start)
+	})
+
+	for next := slowest(roots); next != nil; next = slowest(children[next]) {
+		res.CriticalPath = append(res.CriticalPath, *entries[next])
+	}
+
+	return res
+}
+
+// WriteTable writes the report entries as text table. The nested
+// dependencies are indented under the dependency which required them.
+func (r *StartupReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DEPENDENCY\tNAME\tTOTAL\tSELF")
+	for _, e := range r.Entries {
+		fmt.Fprintf(tw, "%s%s\t%s\t%s\t%s\n",
+			strings.Repeat("  ", e.Depth),
+			getTypeName(e.Registration.Type),
+			e.Registration.Name,
+			e.Duration,
+			e.SelfDuration,
+		)
+	}
+
+	return tw.Flush()
+}
+
+type traceEvent struct {
+	Name      string            `json:"name"`
+	Category  string            `json:"cat"`
+	Phase     string            `json:"ph"`
+	Timestamp int64             `json:"ts"`
+	Duration  int64             `json:"dur"`
+	PID       int               `json:"pid"`
+	TID       int               `json:"tid"`
+	Args      map[string]string `json:"args,omitempty"`
+}
+
+type trace struct {
+	TraceEvents     []traceEvent `json:"traceEvents"`
+	DisplayTimeUnit string       `json:"displayTimeUnit"`
+}
+
+// WriteTrace writes the report entries in the Chrome trace event format
+// which can be opened in chrome://tracing or Perfetto.
+func (r *StartupReport) WriteTrace(w io.Writer) error {
+	res := trace{TraceEvents: []traceEvent{}, DisplayTimeUnit: "ms"}
+	var origin time.Time
+	if len(r.Entries) > 0 {
+		origin = r.Entries[0].Start
+	}
+
+	for _, e := range r.Entries {
+		event := traceEvent{
+			Name:      getTypeName(e.Registration.Type),
+			Category:  "di",
+			Phase:     "X",
+			Timestamp: e.Start.Sub(origin).Microseconds(),
+			Duration:  e.Duration.Microseconds(),
+			PID:       1,
+			TID:       1,
+		}
+		if len(e.Registration.Name) > 0 {
+			event.Args = map[string]string{"name": e.Registration.Name}
+		}
+
+		res.TraceEvents = append(res.TraceEvents, event)
+	}
+
+	return json.NewEncoder(w).Encode(res)
+}
+
+func slowest(deps []*dependencyMetadata) *dependencyMetadata {
+	var res *dependencyMetadata
+	for _, d := range deps {
+		if res == nil || d.timing.duration > res.timing.duration ||
+			(d.timing.duration == res.timing.duration && d.key.String() < res.key.String()) {
+			res = d
+		}
+	}
+
+	return res
+}
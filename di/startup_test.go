@@ -0,0 +1,99 @@
+package di
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStartupReport(t *testing.T) {
+	Convey("StartupReport", t, func() {
+		origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		ticks := 0
+		now = func() time.Time {
+			res := origin.Add(time.Duration(ticks) * time.Millisecond)
+			ticks++
+			return res
+		}
+		Reset(func() { now = time.Now })
+
+		c := NewContainer()
+		err := c.Register(
+			&Dependency{Value: new(firstLevelDependency)},
+			&Dependency{Value: new(secondLevelDependency)},
+			&Dependency{Value: new(pointerDependency)},
+			&Dependency{Value: new(builder)},
+			&Dependency{Value: new(named)},
+		)
+		So(err, ShouldBeNil)
+		err = c.Resolve(new(firstLevelDependency))
+		So(err, ShouldBeNil)
+
+		report := c.StartupReport()
+
+		Convey("Should contain the resolved dependencies in resolve order.", func() {
+			type entry struct {
+				dep          string
+				depth        int
+				duration     time.Duration
+				selfDuration time.Duration
+			}
+
+			res := []entry{}
+			for _, e := range report.Entries {
+				res = append(res, entry{e.Registration.Type.String(), e.Depth, e.Duration, e.SelfDuration})
+			}
+
+			So(res, ShouldResemble, []entry{
+				{"*di.firstLevelDependency", 0, 7 * time.Millisecond, 2 * time.Millisecond},
+				{"*di.secondLevelDependency", 1, 5 * time.Millisecond, 3 * time.Millisecond},
+				{"*di.pointerDependency", 2, time.Millisecond, time.Millisecond},
+				{"*di.builder", 2, time.Millisecond, time.Millisecond},
+			})
+		})
+		Convey("Should contain the critical path.", func() {
+			So(report.CriticalPath, ShouldHaveLength, 3)
+			So(report.CriticalPath[0].Registration.Type.String(), ShouldEqual, "*di.firstLevelDependency")
+			So(report.CriticalPath[1].Registration.Type.String(), ShouldEqual, "*di.secondLevelDependency")
+		})
+		Convey("Should write text table.", func() {
+			buf := new(bytes.Buffer)
+			err := report.WriteTable(buf)
+
+			So(err, ShouldBeNil)
+			So(strings.Split(buf.String(), "\n"), ShouldResemble, []string{
+				"DEPENDENCY                                                          NAME  TOTAL  SELF",
+				"*" + pkgPath + ".firstLevelDependency           7ms    2ms",
+				"  *" + pkgPath + ".secondLevelDependency        5ms    3ms",
+				"    *" + pkgPath + ".pointerDependency          1ms    1ms",
+				"    *" + pkgPath + ".builder                    1ms    1ms",
+				"",
+			})
+		})
+		Convey("Should write trace events.", func() {
+			buf := new(bytes.Buffer)
+			err := report.WriteTrace(buf)
+			So(err, ShouldBeNil)
+
+			res := new(trace)
+			err = json.Unmarshal(buf.Bytes(), res)
+
+			So(err, ShouldBeNil)
+			So(res.DisplayTimeUnit, ShouldEqual, "ms")
+			So(res.TraceEvents, ShouldHaveLength, 4)
+			So(res.TraceEvents[1], ShouldResemble, traceEvent{
+				Name:      "*" + pkgPath + ".secondLevelDependency",
+				Category:  "di",
+				Phase:     "X",
+				Timestamp: 1000,
+				Duration:  5000,
+				PID:       1,
+				TID:       1,
+			})
+		})
+	})
+}
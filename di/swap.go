@@ -0,0 +1,86 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DependencyChangeHandler can be implemented by the dependencies which need
+// to react when Swap changes some of their fields, e.g. to close the old
+// client. The field is the path of the changed field.
+type DependencyChangeHandler interface {
+	OnDependencyChanged(field string, oldValue, newValue interface{})
+}
+
+// Swap replaces the registration which would be injected in field of type t
+// marked with the provided name with the value and injects it in place of
+// the old value in the already resolved dependencies. The value gets the
+// name of the replaced registration and its marked fields are populated
+// before the swap.
+//
+// The fields which no longer hold the old value are not changed. The new
+// instances created with ResolveNew and ResolveNewDeep and the targets of
+// Inject are not tracked by the container and keep the old value.
+//
+// Swap does not synchronize with the code which uses the dependents, so the
+// callers must ensure that the changed fields are not used concurrently.
+func (c *Container) Swap(t reflect.Type, name string, value interface{}) error {
+	old, err := c.findRegistration(t, name)
+	if err != nil {
+		return err
+	}
+
+	meta, err := newDependencyMetadata(&Dependency{Name: old.Name, Value: value})
+	if err != nil {
+		return err
+	}
+
+	if existing, ok := c.dependencies[meta.key]; ok && existing != old {
+		return fmt.Errorf("duplicate dependency: %s", meta.key)
+	}
+
+	for dep, f := range c.dependents[old] {
+		if !meta.reflectValue.Type().AssignableTo(f.field.Type) {
+			return fmt.Errorf("[%s] cannot assign %s to field %s",
+				getTypeName(dep.parent.reflectType), getTypeName(meta.reflectType), f.name)
+		}
+	}
+
+	meta.module = old.module
+	// Register the value before resolving it to track its own dependencies.
+	c.unindex(old)
+	c.dependencies[meta.key] = meta
+	c.indexImplementations(meta)
+	err = c.resolveCore(meta)
+	if err != nil {
+		c.removeDependencies(meta)
+		c.unindex(meta)
+		c.dependencies[old.key] = old
+		// The indexed interfaces are sorted, so old gets its position back.
+		c.indexImplementations(old)
+		return err
+	}
+
+	c.notify(func(o Observer) { o.OnRegister(meta.registration()) })
+	dependents := c.dependents[old]
+	delete(c.dependents, old)
+	c.removeDependencies(old)
+	for dep, f := range dependents {
+		field := fieldByIndex(dep.parent.valueElem, f.index)
+		if !isSameValue(field, old.reflectValue) {
+			continue
+		}
+
+		field.Set(meta.reflectValue)
+		c.addDependent(meta, dep.parent, f)
+		if len(c.observers) > 0 {
+			c.notify(func(o Observer) { o.OnInject(dep.parent.registration(), f.field, meta.registration()) })
+		}
+
+		if h, ok := dep.parent.reflectValue.Interface().(DependencyChangeHandler); ok {
+			h.OnDependencyChanged(f.name, old.reflectValue.Interface(), value)
+		}
+	}
+
+	return nil
+}
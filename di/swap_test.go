@@ -0,0 +1,155 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type credentials struct {
+	token string
+}
+
+type client struct {
+	Credentials *credentials `di:""`
+	Worker      worker       `di:""`
+	changes     []string
+}
+
+func (c *client) OnDependencyChanged(field string, oldValue, newValue interface{}) {
+	c.changes = append(c.changes, fmt.Sprintf("%s %v %v", field, oldValue, newValue))
+}
+
+type rotatingWorker struct {
+	Credentials *credentials `di:""`
+}
+
+func (w *rotatingWorker) Work() string { return w.Credentials.token }
+
+func TestSwap(t *testing.T) {
+	workerType := reflect.TypeOf(new(worker)).Elem()
+
+	Convey("Swap", t, func() {
+		c := NewContainer()
+		cl := new(client)
+		oldCredentials := &credentials{token: "old"}
+		err := c.Register(
+			&Dependency{Value: cl},
+			&Dependency{Value: oldCredentials},
+			&Dependency{Name: "builder", Value: &builder{work: "real"}},
+		)
+		So(err, ShouldBeNil)
+		So(c.ResolveAll(), ShouldBeNil)
+
+		Convey("Should inject the new value in the resolved dependents.", func() {
+			newCredentials := &credentials{token: "new"}
+			err := c.Swap(reflect.TypeOf(newCredentials), "", newCredentials)
+
+			So(err, ShouldBeNil)
+			So(cl.Credentials, ShouldEqual, newCredentials)
+			So(cl.changes, ShouldResemble, []string{fmt.Sprintf("Credentials %v %v", oldCredentials, newCredentials)})
+			So(c.Registrations()[0].Resolved, ShouldBeTrue)
+
+			res := new(credentials)
+			So(c.Resolve(res), ShouldBeNil)
+			So(res.token, ShouldEqual, "new")
+		})
+		Convey("Should resolve the new value and keep tracking it.", func() {
+			w := new(rotatingWorker)
+			err := c.Swap(workerType, "", w)
+
+			So(err, ShouldBeNil)
+			So(cl.Worker.Work(), ShouldEqual, "old")
+			So(c.Registrations(), ShouldHaveLength, 3)
+			So(c.Registrations()[2].Name, ShouldEqual, "builder")
+
+			newCredentials := &credentials{token: "new"}
+			So(c.Swap(reflect.TypeOf(newCredentials), "", newCredentials), ShouldBeNil)
+			So(cl.Worker.Work(), ShouldEqual, "new")
+			So(cl.changes, ShouldHaveLength, 2)
+
+			So(c.Swap(workerType, "builder", &builder{work: "last"}), ShouldBeNil)
+			So(cl.Worker.Work(), ShouldEqual, "last")
+		})
+		Convey("Should not change the fields which no longer hold the old value.", func() {
+			manual := &credentials{token: "manual"}
+			cl.Credentials = manual
+
+			err := c.Swap(reflect.TypeOf(manual), "", &credentials{token: "new"})
+
+			So(err, ShouldBeNil)
+			So(cl.Credentials, ShouldEqual, manual)
+			So(cl.changes, ShouldBeEmpty)
+		})
+		Convey("Should inject values which are not comparable.", func() {
+			type withArray struct {
+				Array [1][]int `di:""`
+			}
+
+			root := new(withArray)
+			err := c.Register(&Dependency{Value: root}, &Dependency{Value: [1][]int{{1}}})
+			So(err, ShouldBeNil)
+			So(c.Resolve(root), ShouldBeNil)
+
+			err = c.Swap(reflect.TypeOf(root.Array), "", [1][]int{{2}})
+
+			So(err, ShouldBeNil)
+			So(root.Array, ShouldResemble, [1][]int{{2}})
+		})
+		Convey("Should return error for", func() {
+			Convey("missing registration.", func() {
+				err := c.Swap(reflect.TypeOf(new(pointerDependency)), "", new(pointerDependency))
+
+				So(err, ShouldBeError, "unable to find registered dependency: *"+pkgPath+".pointerDependency")
+			})
+			Convey("nil type.", func() {
+				err := c.Swap(nil, "", new(pointerDependency))
+
+				So(err, ShouldBeError, "the type must not be nil")
+			})
+			Convey("value which cannot be assigned to the dependents.", func() {
+				err := c.Swap(workerType, "", new(pointerDependency))
+
+				So(err, ShouldBeError, "[*"+pkgPath+".client] cannot assign *"+pkgPath+".pointerDependency to field Worker")
+				So(cl.Worker.Work(), ShouldEqual, "real")
+			})
+			Convey("value which cannot be resolved.", func() {
+				type unresolvable struct {
+					rotatingWorker
+					Missing *pointerDependency `di:""`
+				}
+
+				err := c.Swap(workerType, "", new(unresolvable))
+
+				So(err, ShouldBeError, "[*"+pkgPath+".unresolvable] unable to find registered dependency: Missing")
+				So(cl.Worker.Work(), ShouldEqual, "real")
+
+				var w worker
+				So(c.Resolve(&w), ShouldBeNil)
+				So(w.Work(), ShouldEqual, "real")
+				So(c.Registrations(), ShouldHaveLength, 3)
+			})
+			Convey("value which cannot be resolved without changing the injected implementation.", func() {
+				type unresolvable struct {
+					builder
+					Missing *pointerDependency `di:""`
+				}
+
+				err := c.Register(&Dependency{Name: "other", Value: &builder{work: "other"}})
+				So(err, ShouldBeNil)
+				var w worker
+				So(c.Resolve(&w), ShouldBeNil)
+				So(w.Work(), ShouldEqual, "real")
+
+				err = c.Swap(workerType, "builder", new(unresolvable))
+				So(err, ShouldBeError, "[*"+pkgPath+".unresolvable] unable to find registered dependency: Missing")
+
+				res := new(client)
+				So(c.Inject(res), ShouldBeNil)
+				So(res.Worker.Work(), ShouldEqual, "real")
+			})
+		})
+	})
+}
@@ -1,17 +1,106 @@
 package di
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+	"time"
 
-type diTags struct {
-	name string
+	"github.com/TsvetanMilanov/go-simple-di/internal/tags"
+)
+
+// dependencyKey identifies registered dependency by its type and name.
+type dependencyKey struct {
+	reflectType reflect.Type
+	name        string
+}
+
+func (k dependencyKey) String() string {
+	if k.reflectType == nil {
+		// New instances created by the container are not registered.
+		return ""
+	}
+
+	if len(k.name) > 0 {
+		return fmt.Sprintf("%s (name: %s)", getTypeName(k.reflectType), k.name)
+	}
+
+	return getTypeName(k.reflectType)
+}
+
+type markedField struct {
+	// index is the index sequence of the field, it has more than one
+	// element for fields promoted from embedded structs.
+	index []int
+	// name is the dotted path to the field, e.g. Base.Logger.
+	name     string
+	field    reflect.StructField
+	tags     *tags.Tags
+	settable bool
+	// key is the key of the dependency to inject. It is empty for interfaces.
+	key dependencyKey
+}
+
+// injectsDependency checks if the field is injected with registered
+// dependency and not with configuration or environment value.
+func (f markedField) injectsDependency() bool {
+	return len(f.tags.ValueOption()) == 0
+}
+
+type injectionPlan struct {
+	fields []markedField
+	err    error
 }
 
 type dependencyMetadata struct {
 	*Dependency
+	key          dependencyKey
 	reflectType  reflect.Type
 	reflectValue reflect.Value
 	complete     bool
+	requested    bool
+	fresh        bool
 	typeElem     reflect.Type
 	valueElem    reflect.Value
-	implements   map[string]bool
+	timing       *resolveTiming
+	// reused reports whether the new instance is injected in all fields of
+	// the subtree created by ResolveNewDeep.
+	reused bool
+	// module is the name of the module which installed the dependency.
+	module string
+}
+
+// dependent identifies field of registered dependency injected with another
+// registered dependency.
+type dependent struct {
+	parent *dependencyMetadata
+	field  string
+}
+
+type resolveTiming struct {
+	start    time.Time
+	duration time.Duration
+	// parent is the dependency which was resolving when the resolving of
+	// this dependency started.
+	parent *dependencyMetadata
+}
+
+// isPlainValue checks if the dependency is registered with value which is
+// not pointer, e.g. string or func.
+func (d *dependencyMetadata) isPlainValue() bool {
+	return d.reflectType.Kind() != reflect.Ptr
+}
+
+// registration returns descriptor of the dependency without the implemented
+// interfaces.
+func (d *dependencyMetadata) registration() Registration {
+	return Registration{
+		Name:      d.Name,
+		Key:       d.key.String(),
+		Type:      d.reflectType,
+		Value:     d.Value,
+		Resolved:  d.complete,
+		Shared:    d.Shared,
+		Prototype: d.Prototype,
+		Module:    d.module,
+	}
 }
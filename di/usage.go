@@ -0,0 +1,99 @@
+package di
+
+import (
+	"reflect"
+	"sort"
+)
+
+// UsageReport describes the registrations which are not used and the
+// requirements which cannot be satisfied by the container.
+type UsageReport struct {
+	// Unused contains the registrations which are not referenced by marked
+	// fields of the registered dependencies, were not requested with
+	// Resolve, ResolveByName or ResolveNew and were not injected in the
+	// unregistered instances created by ResolveNew or populated by Inject.
+	Unused []Registration
+	// Unimplemented contains the interfaces required by marked fields of the
+	// registered dependencies which are not implemented by any registered
+	// dependency with matching name.
+	Unimplemented []reflect.Type
+}
+
+// Usage walks the marked fields of all registered dependencies and reports
+// the registrations which are never used.
+// Every registration which can be injected in interface field is considered
+// used, even if another implementation is selected when resolving.
+// Root dependencies which are only populated by ResolveAll are reported as
+// unused, because nothing requests them.
+func (c *Container) Usage() *UsageReport {
+	used := make(map[*dependencyMetadata]bool)
+	unimplemented := make(map[reflect.Type]bool)
+	for _, d := range c.dependencies {
+		if d.requested {
+			used[d] = true
+		}
+
+		fields, err := getMarkedFields(d.typeElem)
+		if err != nil {
+			continue
+		}
+
+		for _, f := range fields {
+			if !f.injectsDependency() {
+				continue
+			}
+
+			candidates := c.findCandidates(f.field.Type, f.tags.Name)
+			if len(f.tags.Default) > 0 {
+				candidates = append(candidates, c.findCandidates(f.field.Type, f.tags.Default)...)
+			}
+			if len(candidates) == 0 && f.field.Type.Kind() == reflect.Interface {
+				unimplemented[f.field.Type] = true
+			}
+
+			for _, candidate := range candidates {
+				used[candidate] = true
+			}
+		}
+	}
+
+	interfaces := c.requiredInterfaces()
+	res := &UsageReport{Unused: []Registration{}, Unimplemented: []reflect.Type{}}
+	for _, d := range c.dependencies {
+		if !used[d] {
+			res.Unused = append(res.Unused, newRegistration(d, interfaces))
+		}
+	}
+
+	for t := range unimplemented {
+		res.Unimplemented = append(res.Unimplemented, t)
+	}
+
+	sortRegistrations(res.Unused)
+	sort.Slice(res.Unimplemented, func(i, j int) bool {
+		return res.Unimplemented[i].String() < res.Unimplemented[j].String()
+	})
+
+	return res
+}
+
+// findCandidates returns all registered dependencies which can be injected
+// in field with the provided type and name.
+func (c *Container) findCandidates(t reflect.Type, name string) []*dependencyMetadata {
+	if t.Kind() != reflect.Interface {
+		if d := c.findDependencyCore(t, name); d != nil {
+			return []*dependencyMetadata{d}
+		}
+
+		return nil
+	}
+
+	res := []*dependencyMetadata{}
+	for _, d := range c.getImplementations(t) {
+		if len(name) == 0 || d.Name == name {
+			res = append(res, d)
+		}
+	}
+
+	return res
+}
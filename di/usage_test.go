@@ -0,0 +1,120 @@
+package di
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUsage(t *testing.T) {
+	Convey("Usage", t, func() {
+		Convey("Should report registrations which are not referenced.", func() {
+			c := NewContainer()
+			unused := &Dependency{Value: new(pointerDependency), Name: "unused"}
+			err := c.Register(
+				&Dependency{Value: new(secondLevelDependency)},
+				&Dependency{Value: new(pointerDependency)},
+				&Dependency{Value: new(builder)},
+				unused,
+			)
+			So(err, ShouldBeNil)
+
+			res := c.Usage()
+
+			So(res.Unimplemented, ShouldBeEmpty)
+			So(res.Unused, ShouldHaveLength, 2)
+			So(res.Unused[0].Name, ShouldEqual, "unused")
+			So(res.Unused[1].Type, ShouldEqual, reflect.TypeOf(new(secondLevelDependency)))
+		})
+		Convey("Should consider the default registrations used.", func() {
+			type withDefault struct {
+				Ptr *pointerDependency `di:"name=custom,default=fallback"`
+			}
+
+			c := NewContainer()
+			err := c.Register(
+				&Dependency{Value: new(withDefault)},
+				&Dependency{Name: "fallback", Value: new(pointerDependency)},
+			)
+			So(err, ShouldBeNil)
+
+			res := c.Usage()
+
+			So(res.Unused, ShouldHaveLength, 1)
+			So(res.Unused[0].Type, ShouldEqual, reflect.TypeOf(new(withDefault)))
+		})
+		Convey("Should consider explicitly requested dependencies used.", func() {
+			c := NewContainer()
+			err := c.Register(
+				&Dependency{Value: new(pointerDependency)},
+				&Dependency{Value: new(builder), Name: "named"},
+			)
+			So(err, ShouldBeNil)
+
+			So(c.Usage().Unused, ShouldHaveLength, 2)
+
+			err = c.Resolve(new(pointerDependency))
+			So(err, ShouldBeNil)
+			err = c.ResolveByName("named", new(worker))
+			So(err, ShouldBeNil)
+
+			So(c.Usage().Unused, ShouldBeEmpty)
+		})
+		Convey("Should consider dependencies of unregistered instances used.", func() {
+			type handler struct {
+				Ptr    *pointerDependency `di:""`
+				Worker worker             `di:""`
+			}
+
+			c := NewContainer()
+			err := c.Register(
+				&Dependency{Value: new(pointerDependency)},
+				&Dependency{Value: new(builder)},
+			)
+			So(err, ShouldBeNil)
+
+			Convey("With ResolveNew.", func() {
+				So(c.ResolveNew(new(handler)), ShouldBeNil)
+
+				So(c.Usage().Unused, ShouldBeEmpty)
+			})
+			Convey("With Inject.", func() {
+				So(c.Inject(new(handler)), ShouldBeNil)
+
+				So(c.Usage().Unused, ShouldBeEmpty)
+			})
+		})
+		Convey("Should consider all implementations of required interface used.", func() {
+			c := NewContainer()
+			err := c.Register(
+				&Dependency{Value: new(rootDependency)},
+				&Dependency{Value: new(firstLevelDependency)},
+				&Dependency{Value: new(secondLevelDependency)},
+				&Dependency{Value: new(pointerDependency)},
+				&Dependency{Value: new(builder), Name: "first"},
+				&Dependency{Value: new(builder), Name: "second"},
+			)
+			So(err, ShouldBeNil)
+
+			res := c.Usage()
+
+			So(res.Unused, ShouldHaveLength, 1)
+			So(res.Unused[0].Type, ShouldEqual, reflect.TypeOf(new(rootDependency)))
+		})
+		Convey("Should report interfaces which are not implemented.", func() {
+			c := NewContainer()
+			err := c.Register(
+				&Dependency{Value: new(named)},
+				&Dependency{Value: new(pointerDependency), Name: "test1"},
+				&Dependency{Value: new(builder)},
+			)
+			So(err, ShouldBeNil)
+
+			res := c.Usage()
+
+			So(res.Unimplemented, ShouldResemble, []reflect.Type{reflect.TypeOf((*worker)(nil)).Elem()})
+			So(res.Unused, ShouldHaveLength, 2)
+		})
+	})
+}
@@ -3,13 +3,15 @@ package di
 import (
 	"fmt"
 	"reflect"
-	"strings"
-)
+	"sync"
+	"time"
 
-const (
-	diTagName = "di"
+	"github.com/TsvetanMilanov/go-simple-di/internal/tags"
 )
 
+// now returns the current time. It is replaced in tests.
+var now = time.Now
+
 // isFieldExported checks if the provided field is exported.
 // https://golang.org/pkg/reflect/#StructField
 func isFieldExported(f reflect.StructField) bool {
@@ -19,69 +21,240 @@ func isFieldExported(f reflect.StructField) bool {
 func generateDependencyMetadata(d *Dependency) *dependencyMetadata {
 	vType := reflect.TypeOf(d.Value)
 	value := reflect.ValueOf(d.Value)
+	typeElem, valueElem := vType, value
+	if vType.Kind() == reflect.Ptr {
+		typeElem, valueElem = vType.Elem(), value.Elem()
+	}
 
 	return &dependencyMetadata{
 		Dependency:   d,
 		reflectType:  vType,
 		reflectValue: value,
-		typeElem:     vType.Elem(),
-		valueElem:    value.Elem(),
-		implements:   make(map[string]bool),
+		typeElem:     typeElem,
+		valueElem:    valueElem,
 	}
 }
 
-func getDependencyKey(t reflect.Type, name string) string {
-	key := fmt.Sprintf("%s-%s-%s",
-		t.PkgPath(),
-		t.String(),
-		t.Kind(),
-	)
-	if len(name) > 0 {
-		return fmt.Sprintf("%s-%s", key, name)
+// cloneValue returns copy of the value of the provided dependency. The copy
+// is created with the Clone method of the value if it has one with matching
+// signature and it does not return nil. Otherwise the value is copied
+// shallowly, except for the embedded struct pointers which contain marked
+// fields. They are copied too, so the injection in the copy does not change
+// the value.
+func cloneValue(d *dependencyMetadata) interface{} {
+	if m, ok := d.reflectType.MethodByName("Clone"); ok &&
+		m.Type.NumIn() == 1 && m.Type.NumOut() == 1 && m.Type.Out(0) == d.reflectType {
+		res := d.reflectValue.Method(m.Index).Call(nil)[0]
+		if !res.IsNil() {
+			return res.Interface()
+		}
 	}
 
-	return key
+	res := reflect.New(d.typeElem)
+	res.Elem().Set(d.valueElem)
+	copyEmbeddedPointers(res.Elem())
+	return res.Interface()
 }
 
-func getTags(field reflect.StructField) (*diTags, error) {
-	tag, ok := field.Tag.Lookup(diTagName)
-	if !ok {
-		return nil, nil
+// copyEmbeddedPointers replaces the embedded pointers on the paths to the
+// marked fields of v with pointers to copies of their values.
+func copyEmbeddedPointers(v reflect.Value) {
+	fields, err := getMarkedFields(v.Type())
+	if err != nil {
+		return
 	}
 
-	tags := strings.Split(tag, ",")
-	res := new(diTags)
-	// Check for empty tags.
-	if len(tags) == 1 && len(tags[0]) == 0 {
-		// Return default values.
-		return res, nil
+	copied := make(map[uintptr]bool)
+	for _, f := range fields {
+		field := v
+		for i, x := range f.index {
+			if i > 0 && field.Kind() == reflect.Ptr {
+				if field.IsNil() || !field.CanSet() {
+					break
+				}
+
+				if !copied[field.Pointer()] {
+					c := reflect.New(field.Type().Elem())
+					c.Elem().Set(field.Elem())
+					field.Set(c)
+					copied[c.Pointer()] = true
+				}
+
+				field = field.Elem()
+			}
+
+			field = field.Field(x)
+		}
 	}
+}
+
+func getDependencyKey(t reflect.Type, name string) dependencyKey {
+	return dependencyKey{reflectType: t, name: name}
+}
 
-	for _, tag := range tags {
-		tagContent := strings.Split(tag, "=")
-		if len(tagContent) != 2 {
-			return nil, getInvalidTagErr(tag)
+// getTypeName returns the name of the provided type qualified with the full
+// import path of the packages of the named types.
+func getTypeName(t reflect.Type) string {
+	if len(t.Name()) > 0 {
+		if len(t.PkgPath()) > 0 {
+			return t.PkgPath() + "." + t.Name()
 		}
 
-		k := tagContent[0]
-		v := tagContent[1]
-		if len(v) == 0 {
-			return nil, getInvalidTagErr(tag)
+		return t.Name()
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + getTypeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + getTypeName(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), getTypeName(t.Elem()))
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", getTypeName(t.Key()), getTypeName(t.Elem()))
+	}
+
+	return t.String()
+}
+
+func getTags(field reflect.StructField) (*tags.Tags, error) {
+	return tags.Lookup(field.Tag)
+}
+
+// injectionPlans caches the *injectionPlan of each resolved struct type.
+var injectionPlans sync.Map
+
+// getMarkedFields returns the fields of the provided type which are marked
+// for injection with the di tag. The result is cached per type and should
+// not be modified.
+func getMarkedFields(t reflect.Type) ([]markedField, error) {
+	if p, ok := injectionPlans.Load(t); ok {
+		plan := p.(*injectionPlan)
+		return plan.fields, plan.err
+	}
+
+	plan := newInjectionPlan(t)
+	injectionPlans.Store(t, plan)
+	return plan.fields, plan.err
+}
+
+func newInjectionPlan(t reflect.Type) *injectionPlan {
+	if t.Kind() != reflect.Struct {
+		return &injectionPlan{}
+	}
+
+	res := []markedField{}
+	err := collectMarkedFields(t, nil, "", false, map[reflect.Type]bool{t: true}, &res)
+	if err != nil {
+		return &injectionPlan{err: err}
+	}
+
+	return &injectionPlan{fields: res}
+}
+
+// collectMarkedFields appends the marked fields of the provided struct type
+// to res. The fields of untagged embedded structs and pointers to structs
+// are collected recursively with their index sequence. The fields promoted
+// through unexported embedded pointers cannot be set because reflect does
+// not allow allocating these pointers.
+func collectMarkedFields(t reflect.Type, index []int, path string, readOnly bool, visited map[reflect.Type]bool, res *[]markedField) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldTags, err := getTags(field)
+		if err != nil {
+			return err
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+		fieldName := path + field.Name
+		if fieldTags == nil {
+			embedded, isPtr := getEmbeddedStruct(field)
+			if embedded == nil || visited[embedded] {
+				continue
+			}
+
+			visited[embedded] = true
+			embeddedReadOnly := readOnly || (isPtr && !isFieldExported(field))
+			err = collectMarkedFields(embedded, fieldIndex, fieldName+".", embeddedReadOnly, visited, res)
+			delete(visited, embedded)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err = validateTags(field, fieldTags)
+		if err != nil {
+			return err
 		}
 
-		switch k {
-		case "name":
-			res.name = v
-		default:
-			return nil, getInvalidTagErr(tag)
+		f := markedField{
+			index:    fieldIndex,
+			name:     fieldName,
+			field:    field,
+			tags:     fieldTags,
+			settable: !readOnly && isInjectableType(field.Type) && isFieldExported(field),
+		}
+		if field.Type.Kind() != reflect.Interface && f.injectsDependency() {
+			// Interfaces are looked up in the implementations index.
+			f.key = getDependencyKey(field.Type, fieldTags.Name)
+		}
+
+		*res = append(*res, f)
+	}
+
+	return nil
+}
+
+// getEmbeddedStruct returns the struct type of embedded struct or pointer
+// to struct field and whether the field is pointer.
+func getEmbeddedStruct(field reflect.StructField) (reflect.Type, bool) {
+	if !field.Anonymous {
+		return nil, false
+	}
+
+	t := field.Type
+	isPtr := t.Kind() == reflect.Ptr
+	if isPtr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	return t, isPtr
+}
+
+// fieldByIndex returns the nested field of v with the provided index
+// sequence. Unlike reflect.Value.FieldByIndex it allocates the nil
+// embedded pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
 		}
+
+		v = v.Field(x)
 	}
 
-	return res, nil
+	return v
 }
 
-func getInvalidTagErr(tag string) error {
-	return fmt.Errorf("invalid tag configuration '%s', expecting <key>=<value>", tag)
+// validateTags checks for options which are valid in the di tag grammar but
+// are not supported by the container or cannot be combined.
+func validateTags(field reflect.StructField, t *tags.Tags) error {
+	err := t.Validate()
+	if err != nil {
+		return fmt.Errorf("%s for field %s", err.Error(), field.Name)
+	}
+
+	return nil
 }
 
 func isValidValue(t reflect.Type) (isValid bool) {
@@ -95,6 +268,13 @@ func isValidValue(t reflect.Type) (isValid bool) {
 	return kind == reflect.Ptr || kind == reflect.Interface
 }
 
+// isInjectableType checks if values of the provided type can be registered
+// and injected. Struct values are not supported because they are copied on
+// injection, use pointers to structs instead.
+func isInjectableType(t reflect.Type) bool {
+	return t.Kind() != reflect.Struct
+}
+
 func isPointerTypePointerToInterface(t reflect.Type) bool {
 	return t.Elem().Kind() == reflect.Interface
 }
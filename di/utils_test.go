@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/TsvetanMilanov/go-simple-di/internal/tags"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -52,7 +53,7 @@ func TestUtils(t *testing.T) {
 			Convey("Should parse tags correctly when", func() {
 				type testCase struct {
 					input    interface{}
-					expected diTags
+					expected tags.Tags
 				}
 
 				testCases := map[string]testCase{
@@ -60,7 +61,13 @@ func TestUtils(t *testing.T) {
 						input: struct {
 							F int `di:"name=test"`
 						}{},
-						expected: diTags{name: "test"},
+						expected: tags.Tags{Name: "test"},
+					},
+					"parsing flags.": {
+						input: struct {
+							F int `di:"optional,name='a,b'"`
+						}{},
+						expected: tags.Tags{Name: "a,b", Optional: true},
 					},
 				}
 
@@ -83,34 +90,34 @@ func TestUtils(t *testing.T) {
 			})
 			Convey("Should return error when", func() {
 				type testCase struct {
-					input              interface{}
-					expectedErrorValue string
+					input         interface{}
+					expectedError string
 				}
 
 				testCases := map[string]testCase{
 					"the tag contains only key.": {
 						input: struct {
-							F int `di:"key"`
+							F int `di:"name"`
 						}{},
-						expectedErrorValue: "key",
+						expectedError: "invalid di tag 'name' at position 5: option 'name' requires value",
 					},
 					"the tag contains only value.": {
 						input: struct {
 							F int `di:"=value"`
 						}{},
-						expectedErrorValue: "=value",
+						expectedError: "invalid di tag '=value' at position 1: unexpected character '='",
 					},
 					"the tag does not contain value.": {
 						input: struct {
-							F int `di:"key="`
+							F int `di:"name="`
 						}{},
-						expectedErrorValue: "key=",
+						expectedError: "invalid di tag 'name=' at position 6: expected value",
 					},
 					"the tag does not contain valid key.": {
 						input: struct {
 							F int `di:"key=value"`
 						}{},
-						expectedErrorValue: "key=value",
+						expectedError: "invalid di tag 'key=value' at position 1: unknown option 'key'",
 					},
 				}
 
@@ -119,11 +126,143 @@ func TestUtils(t *testing.T) {
 						f := getStructField(tc.input)
 						res, err := getTags(f)
 
-						So(err, ShouldBeError, getInvalidTagErr(tc.expectedErrorValue))
+						So(err, ShouldBeError, tc.expectedError)
 						So(res, ShouldBeNil)
 					})
 				}
 			})
 		})
+
+		Convey("getMarkedFields", func() {
+			Convey("Should return the marked fields with lookup keys.", func() {
+				type marked struct {
+					Ptr       *pointerDependency `di:"name=ptr"`
+					NotMarked *pointerDependency
+					Interface worker             `di:""`
+					unexp     *pointerDependency `di:""`
+				}
+
+				res, err := getMarkedFields(reflect.TypeOf(marked{}))
+
+				So(err, ShouldBeNil)
+				So(res, ShouldHaveLength, 3)
+				So(res[0].index, ShouldResemble, []int{0})
+				So(res[0].settable, ShouldBeTrue)
+				So(res[0].key, ShouldResemble, getDependencyKey(reflect.TypeOf(new(pointerDependency)), "ptr"))
+				So(res[1].index, ShouldResemble, []int{2})
+				So(res[1].settable, ShouldBeTrue)
+				So(res[1].key, ShouldResemble, dependencyKey{})
+				So(res[2].index, ShouldResemble, []int{3})
+				So(res[2].settable, ShouldBeFalse)
+			})
+			Convey("Should return the marked fields of embedded structs.", func() {
+				type base struct {
+					Ptr *pointerDependency `di:""`
+				}
+				type Base struct {
+					Ptr *pointerDependency `di:""`
+				}
+				type embedding struct {
+					base
+					*Base
+					Tagged *Base `di:""`
+				}
+				type unexportedPtr struct {
+					*base
+				}
+
+				res, err := getMarkedFields(reflect.TypeOf(embedding{}))
+
+				So(err, ShouldBeNil)
+				So(res, ShouldHaveLength, 3)
+				So(res[0].index, ShouldResemble, []int{0, 0})
+				So(res[0].name, ShouldEqual, "base.Ptr")
+				So(res[0].settable, ShouldBeTrue)
+				So(res[1].index, ShouldResemble, []int{1, 0})
+				So(res[1].name, ShouldEqual, "Base.Ptr")
+				So(res[1].settable, ShouldBeTrue)
+				So(res[2].index, ShouldResemble, []int{2})
+				So(res[2].name, ShouldEqual, "Tagged")
+
+				res, err = getMarkedFields(reflect.TypeOf(unexportedPtr{}))
+
+				So(err, ShouldBeNil)
+				So(res, ShouldHaveLength, 1)
+				So(res[0].settable, ShouldBeFalse)
+			})
+			Convey("Should not recurse infinitely in self embedding structs.", func() {
+				type recursive struct {
+					*recursive
+					Ptr *pointerDependency `di:""`
+				}
+
+				res, err := getMarkedFields(reflect.TypeOf(recursive{}))
+
+				So(err, ShouldBeNil)
+				So(res, ShouldHaveLength, 1)
+				So(res[0].index, ShouldResemble, []int{1})
+			})
+			Convey("Should cache the result per type.", func() {
+				t := reflect.TypeOf(rootDependency{})
+				first, err := getMarkedFields(t)
+				So(err, ShouldBeNil)
+
+				second, err := getMarkedFields(t)
+				So(err, ShouldBeNil)
+
+				So(&second[0], ShouldEqual, &first[0])
+			})
+			Convey("Should cache tag errors.", func() {
+				type invalid struct {
+					F *pointerDependency `di:"key"`
+				}
+
+				t := reflect.TypeOf(invalid{})
+				_, first := getMarkedFields(t)
+				_, second := getMarkedFields(t)
+
+				So(first, ShouldBeError, "invalid di tag 'key' at position 1: unknown option 'key'")
+				So(second, ShouldEqual, first)
+			})
+			Convey("Should return error for unsupported options.", func() {
+				type unsupported struct {
+					F *pointerDependency `di:"lazy"`
+				}
+
+				_, err := getMarkedFields(reflect.TypeOf(unsupported{}))
+
+				So(err, ShouldBeError, "unsupported di tag option 'lazy' for field F")
+			})
+			Convey("Should return no fields for non struct types.", func() {
+				res, err := getMarkedFields(reflect.TypeOf(5))
+
+				So(err, ShouldBeNil)
+				So(res, ShouldBeEmpty)
+			})
+		})
+
+		Convey("getTypeName", func() {
+			Convey("Should qualify named types with the import path.", func() {
+				type testCase struct {
+					input    interface{}
+					expected string
+				}
+
+				testCases := map[string]testCase{
+					"pointers.":   {new(pointerDependency), "*" + pkgPath + ".pointerDependency"},
+					"slices.":     {[]*builder{}, "[]*" + pkgPath + ".builder"},
+					"arrays.":     {[2]int{}, "[2]int"},
+					"maps.":       {map[string]*named{}, "map[string]*" + pkgPath + ".named"},
+					"predeclared": {"", "string"},
+					"functions.":  {func() {}, "func()"},
+				}
+
+				for testName, tc := range testCases {
+					Convey(testName, func() {
+						So(getTypeName(reflect.TypeOf(tc.input)), ShouldEqual, tc.expected)
+					})
+				}
+			})
+		})
 	})
 }
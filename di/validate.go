@@ -0,0 +1,84 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Validate checks that the marked fields of all registered dependencies can
+// be injected, without resolving them. It returns error which describes all
+// fields with missing dependencies, invalid tags or fields which cannot be
+// set. The values of the config and env fields are not checked. The
+// interface fields are valid when the mock factory can mock them, the mocks
+// created to check it are discarded.
+func (c *Container) Validate() error {
+	deps := make([]*dependencyMetadata, 0, len(c.dependencies))
+	for _, d := range c.dependencies {
+		deps = append(deps, d)
+	}
+
+	sort.Slice(deps, func(i, j int) bool { return deps[i].key.String() < deps[j].key.String() })
+
+	errs := []string{}
+	for _, d := range deps {
+		fields, err := getMarkedFields(d.typeElem)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("[%s] %s", getTypeName(d.reflectType), err.Error()))
+			continue
+		}
+
+		for _, f := range fields {
+			err = c.validateField(f)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("[%s] %s", getTypeName(d.reflectType), err.Error()))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+// validateField checks if the field can be injected like resolveFields
+// would inject it.
+func (c *Container) validateField(f markedField) error {
+	if !f.settable {
+		return fmt.Errorf("cannot set field %s", f.name)
+	}
+
+	if !f.injectsDependency() || f.tags.Optional {
+		return nil
+	}
+
+	if c.findFieldDependency(f) != nil {
+		return nil
+	}
+
+	if f.tags.New && f.field.Type.Kind() == reflect.Ptr {
+		// New instance of the field type is created.
+		return nil
+	}
+
+	isInterface := f.field.Type.Kind() == reflect.Interface
+
+	if f.tags.HasDefault {
+		if !isInterface || (len(f.tags.Default) > 0 && c.findDependencyCore(f.field.Type, f.tags.Default) != nil) {
+			return nil
+		}
+	}
+
+	if isInterface && c.mockFactory != nil {
+		ok, err := c.canMock(f.field.Type)
+		if err != nil || ok {
+			return err
+		}
+	}
+
+	return fmt.Errorf("unable to find registered dependency: %s", f.name)
+}
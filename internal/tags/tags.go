@@ -0,0 +1,296 @@
+// Package tags parses the di struct tags. It is shared by the di container
+// and the tools which work with the di struct tags.
+//
+// The grammar of the di tag is:
+//
+//	tag    = [ option { "," option } ] .
+//	option = flag | key "=" value .
+//	flag   = "optional" | "required" | "lazy" | "new" | "all" .
+//	key    = "name" | "group" | "default" | "env" | "config" .
+//	value  = bare | quoted .
+//	bare   = non-empty sequence of characters except "," "=" and "'" .
+//	quoted = "'" { character | "\'" | "\\" } "'" .
+//
+// Spaces around the options are ignored. Each option can be set once.
+package tags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Key is the struct tag key which marks the fields for injection.
+const Key = "di"
+
+// Tags contains the options of di struct tag.
+type Tags struct {
+	// Name is the name of the dependency to inject.
+	Name string
+	// Group is the name of the group of dependencies to inject.
+	Group string
+	// Default is the value of the default option.
+	Default string
+	// HasDefault reports whether the default option is set. It allows
+	// setting empty default value with quotes.
+	HasDefault bool
+	// Env is the name of the environment variable to inject.
+	Env string
+	// Config is the key of the configuration value to inject.
+	Config string
+
+	Optional bool
+	Required bool
+	Lazy     bool
+	New      bool
+	All      bool
+}
+
+// SyntaxError describes invalid di tag.
+type SyntaxError struct {
+	// Tag is the value of the invalid di tag.
+	Tag string
+	// Pos is the 1-based position of the invalid character in Tag.
+	Pos int
+	// Msg describes the error.
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid di tag '%s' at position %d: %s", e.Tag, e.Pos, e.Msg)
+}
+
+// Lookup parses the di tag from the provided struct tag.
+// It returns nil result and nil error when the struct tag does not contain
+// di tag.
+func Lookup(tag reflect.StructTag) (*Tags, error) {
+	value, ok := tag.Lookup(Key)
+	if !ok {
+		return nil, nil
+	}
+
+	return Parse(value)
+}
+
+// Parse parses the value of di struct tag.
+func Parse(tag string) (*Tags, error) {
+	p := &parser{tag: tag, seen: make(map[string]bool)}
+	res := new(Tags)
+	if len(strings.TrimSpace(tag)) == 0 {
+		return res, nil
+	}
+
+	for {
+		err := p.parseOption(res)
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipSpaces()
+		if p.done() {
+			return res, nil
+		}
+
+		if p.tag[p.pos] != ',' {
+			return nil, p.errorf(p.pos, "expected ',' but found '%c'", p.tag[p.pos])
+		}
+
+		p.pos++
+	}
+}
+
+// ValueOption returns the option which injects value instead of registered
+// dependency, "config" or "env", or empty string for the dependency fields.
+func (t *Tags) ValueOption() string {
+	switch {
+	case len(t.Config) > 0:
+		return "config"
+	case len(t.Env) > 0:
+		return "env"
+	}
+
+	return ""
+}
+
+// Validate checks for options which are valid in the grammar but are not
+// supported by the di container or cannot be used together.
+func (t *Tags) Validate() error {
+	valueOption := t.ValueOption()
+	switch {
+	case t.Lazy:
+		return errors.New("unsupported di tag option 'lazy'")
+	case t.All:
+		return errors.New("unsupported di tag option 'all'")
+	case len(t.Group) > 0:
+		return errors.New("unsupported di tag option 'group'")
+	case t.HasDefault && len(valueOption) == 0 && (t.New || t.Optional):
+		return errors.New("di tag option 'default' cannot be used with 'new' or 'optional'")
+	case len(t.Env) > 0 && len(t.Config) > 0:
+		return errors.New("di tag options 'env' and 'config' cannot be used together")
+	case len(valueOption) > 0 && (len(t.Name) > 0 || t.New):
+		return fmt.Errorf("di tag option '%s' cannot be used with 'name' or 'new'", valueOption)
+	case t.Required && len(valueOption) == 0:
+		return errors.New("di tag option 'required' can be used only with 'env' or 'config'")
+	}
+
+	return nil
+}
+
+type parser struct {
+	tag  string
+	pos  int
+	seen map[string]bool
+}
+
+func (p *parser) parseOption(res *Tags) error {
+	p.skipSpaces()
+	start := p.pos
+	for !p.done() && isIdentChar(p.tag[p.pos]) {
+		p.pos++
+	}
+
+	option := p.tag[start:p.pos]
+	if len(option) == 0 {
+		if p.done() || p.tag[p.pos] == ',' {
+			return p.errorf(start, "empty option")
+		}
+
+		return p.errorf(start, "unexpected character '%c'", p.tag[start])
+	}
+
+	if p.seen[option] {
+		return p.errorf(start, "duplicate option '%s'", option)
+	}
+
+	p.seen[option] = true
+	if flag := findFlag(res, option); flag != nil {
+		p.skipSpaces()
+		if !p.done() && p.tag[p.pos] == '=' {
+			return p.errorf(p.pos, "option '%s' does not accept value", option)
+		}
+
+		*flag = true
+		return nil
+	}
+
+	value := findKey(res, option)
+	if value == nil {
+		return p.errorf(start, "unknown option '%s'", option)
+	}
+
+	p.skipSpaces()
+	if p.done() || p.tag[p.pos] != '=' {
+		return p.errorf(p.pos, "option '%s' requires value", option)
+	}
+
+	p.pos++
+	p.skipSpaces()
+	v, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	*value = v
+	if option == "default" {
+		res.HasDefault = true
+	}
+
+	return nil
+}
+
+func (p *parser) parseValue() (string, error) {
+	if !p.done() && p.tag[p.pos] == '\'' {
+		return p.parseQuoted()
+	}
+
+	start := p.pos
+	for !p.done() && !strings.ContainsRune(",='", rune(p.tag[p.pos])) {
+		p.pos++
+	}
+
+	value := strings.TrimRight(p.tag[start:p.pos], " ")
+	if len(value) == 0 {
+		return "", p.errorf(start, "expected value")
+	}
+
+	if !p.done() && p.tag[p.pos] != ',' {
+		return "", p.errorf(p.pos, "unexpected character '%c' in value, use quotes", p.tag[p.pos])
+	}
+
+	return value, nil
+}
+
+func (p *parser) parseQuoted() (string, error) {
+	start := p.pos
+	p.pos++
+	res := new(strings.Builder)
+	for !p.done() {
+		c := p.tag[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.tag) && (p.tag[p.pos+1] == '\'' || p.tag[p.pos+1] == '\\'):
+			res.WriteByte(p.tag[p.pos+1])
+			p.pos += 2
+		case c == '\'':
+			p.pos++
+			return res.String(), nil
+		default:
+			res.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	return "", p.errorf(start, "unterminated quoted value")
+}
+
+func (p *parser) skipSpaces() {
+	for !p.done() && p.tag[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tag)
+}
+
+func (p *parser) errorf(pos int, format string, args ...interface{}) error {
+	return &SyntaxError{Tag: p.tag, Pos: pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+func isIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func findFlag(t *Tags, option string) *bool {
+	switch option {
+	case "optional":
+		return &t.Optional
+	case "required":
+		return &t.Required
+	case "lazy":
+		return &t.Lazy
+	case "new":
+		return &t.New
+	case "all":
+		return &t.All
+	}
+
+	return nil
+}
+
+func findKey(t *Tags, option string) *string {
+	switch option {
+	case "name":
+		return &t.Name
+	case "group":
+		return &t.Group
+	case "default":
+		return &t.Default
+	case "env":
+		return &t.Env
+	case "config":
+		return &t.Config
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package tags
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTags(t *testing.T) {
+	Convey("Tags", t, func() {
+		Convey("Lookup", func() {
+			Convey("Should parse the di tag.", func() {
+				res, err := Lookup(reflect.StructTag(`json:"f" di:"name=test"`))
+
+				So(err, ShouldBeNil)
+				So(*res, ShouldResemble, Tags{Name: "test"})
+			})
+			Convey("Should return nil result and nil error when there is no di tag.", func() {
+				res, err := Lookup(reflect.StructTag(`json:"f"`))
+
+				So(err, ShouldBeNil)
+				So(res, ShouldBeNil)
+			})
+		})
+
+		Convey("Parse", func() {
+			Convey("Should parse", func() {
+				type testCase struct {
+					input    string
+					expected Tags
+				}
+
+				testCases := map[string]testCase{
+					"empty tag.":          {"", Tags{}},
+					"only spaces.":        {"  ", Tags{}},
+					"name.":               {"name=test", Tags{Name: "test"}},
+					"all keys.":           {"name=n,group=g,default=d,env=E,config=c", Tags{Name: "n", Group: "g", Default: "d", HasDefault: true, Env: "E", Config: "c"}},
+					"all flags.":          {"optional,required,lazy,new,all", Tags{Optional: true, Required: true, Lazy: true, New: true, All: true}},
+					"flags and keys.":     {"optional,name=test", Tags{Optional: true, Name: "test"}},
+					"spaces around.":      {" optional , name = test ", Tags{Optional: true, Name: "test"}},
+					"spaces in value.":    {"default=a b", Tags{Default: "a b", HasDefault: true}},
+					"quoted value.":       {"default='a,b=c'", Tags{Default: "a,b=c", HasDefault: true}},
+					"empty quoted value.": {"default=''", Tags{HasDefault: true}},
+					"escaped quotes.":     {`default='it\'s \\ ok',name=x`, Tags{Default: `it's \ ok`, HasDefault: true, Name: "x"}},
+				}
+
+				for testName, tc := range testCases {
+					Convey(testName, func() {
+						res, err := Parse(tc.input)
+
+						So(err, ShouldBeNil)
+						So(*res, ShouldResemble, tc.expected)
+					})
+				}
+			})
+			Convey("Should return syntax error with position when", func() {
+				type testCase struct {
+					input    string
+					pos      int
+					expected string
+				}
+
+				testCases := map[string]testCase{
+					"the option is unknown.":                  {"name=a,key=value", 8, "unknown option 'key'"},
+					"the key does not have value.":            {"name", 5, "option 'name' requires value"},
+					"the value is empty.":                     {"name=", 6, "expected value"},
+					"the flag has value.":                     {"optional=true", 9, "option 'optional' does not accept value"},
+					"the option is empty.":                    {"name=a,,new", 8, "empty option"},
+					"the tag ends with comma.":                {"new,", 5, "empty option"},
+					"the option starts with invalid char.":    {"=value", 1, "unexpected character '='"},
+					"the option is duplicated.":               {"new,new", 5, "duplicate option 'new'"},
+					"the value contains equal sign.":          {"name=a=b", 7, "unexpected character '=' in value, use quotes"},
+					"the quoted value is not terminated.":     {"name='abc", 6, "unterminated quoted value"},
+					"the quoted value is followed by text.":   {"name='a'b", 9, "expected ',' but found 'b'"},
+					"the value contains quote.":               {"name=a'b", 7, "unexpected character ''' in value, use quotes"},
+					"the option contains upper case letters.": {"Name=a", 1, "unexpected character 'N'"},
+				}
+
+				for testName, tc := range testCases {
+					Convey(testName, func() {
+						res, err := Parse(tc.input)
+
+						So(res, ShouldBeNil)
+						So(err, ShouldResemble, &SyntaxError{Tag: tc.input, Pos: tc.pos, Msg: tc.expected})
+					})
+				}
+			})
+			Convey("Should format syntax errors.", func() {
+				_, err := Parse("name=a,key=value")
+
+				So(err, ShouldBeError, "invalid di tag 'name=a,key=value' at position 8: unknown option 'key'")
+			})
+		})
+
+		Convey("Validate", func() {
+			Convey("Should accept the supported options.", func() {
+				for _, tag := range []string{"", "name=a,optional", "new", "default=a", "env=A,default=1,required", "config=a,optional"} {
+					res, err := Parse(tag)
+					So(err, ShouldBeNil)
+					So(res.Validate(), ShouldBeNil)
+				}
+			})
+			Convey("Should return error for", func() {
+				testCases := map[string]struct {
+					input    string
+					expected string
+				}{
+					"unsupported option.": {input: "lazy", expected: "unsupported di tag option 'lazy'"},
+					"default and new.":    {input: "new,default=a", expected: "di tag option 'default' cannot be used with 'new' or 'optional'"},
+					"env and config.":     {input: "env=A,config=a", expected: "di tag options 'env' and 'config' cannot be used together"},
+					"config and name.":    {input: "config=a,name=a", expected: "di tag option 'config' cannot be used with 'name' or 'new'"},
+					"required.":           {input: "required", expected: "di tag option 'required' can be used only with 'env' or 'config'"},
+				}
+
+				for testName, tc := range testCases {
+					Convey(testName, func() {
+						res, err := Parse(tc.input)
+						So(err, ShouldBeNil)
+
+						So(res.Validate(), ShouldBeError, tc.expected)
+					})
+				}
+			})
+		})
+	})
+}